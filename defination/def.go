@@ -4,6 +4,24 @@ type RemoteComponent interface {
 	RemoteServiceId() string
 }
 
+// RemoteVersion is implemented by remote components that export a semantic
+// version of their service. Several versions of a service can be exported
+// side by side.
+type RemoteVersion interface {
+	RemoteServiceVersion() string
+}
+
+// RemoteNamespace and RemoteGroup place a remote component in a namespace
+// and a group, services of the same id in other ones do not collide. Invoke
+// components implement them to resolve only the services placed alike.
+type RemoteNamespace interface {
+	RemoteServiceNamespace() string
+}
+
+type RemoteGroup interface {
+	RemoteServiceGroup() string
+}
+
 type RemoteMethodExport interface {
 	ExportMethods() []string
 }
@@ -18,3 +36,10 @@ type InvokeComponent interface {
 	RemoteServiceId() string
 	RegisterInvoker(invoke Invoke)
 }
+
+// InvokeVersion is implemented by invoke components that choose among the
+// exported versions of their service with a semver constraint, such as
+// "^1.2" or ">=1.0 <3". The highest matching version is invoked.
+type InvokeVersion interface {
+	RemoteVersionConstraint() string
+}
@@ -1,145 +1,502 @@
 package client
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/go-kid/ioc/registry"
 	"github.com/go-kid/ioc/scanner/meta"
 	"github.com/go-kid/remote-ioc/defination"
+	"github.com/go-kid/remote-ioc/http/codec"
 	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/go-kid/remote-ioc/http/discovery"
 	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/semver"
 	"github.com/go-kid/remote-ioc/http/transmission"
+	"github.com/go-kid/remote-ioc/http/validation"
 	"github.com/go-resty/resty/v2"
 	"github.com/samber/lo"
+	"log"
+	"net"
+	"net/http"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type iocClient struct {
 	c        Config
 	r        registry.Registry
-	servers  map[string]*serverMeta
+	Props    *Properties `prop:"remote-ioc.client"`
 	invokers map[string]*clientComponent
 
 	client *resty.Client
+	// ctx bounds the discovery watches, Close cancels it
+	ctx     context.Context
+	cancel  context.CancelFunc
+	watches sync.WaitGroup
+
+	// balance guards the Delay of the servers, written by the calls and
+	// read by the load balancing
+	balance sync.Mutex
+
+	mu sync.Mutex
+	// probes caches the servers probed while initializing by instance URL
+	probes map[string]*probe
 }
 
 func (s *iocClient) Init() error {
-	s.servers = make(map[string]*serverMeta)
-	s.client = resty.New()
-
-	err := s.registerServers()
+	c, err := s.c.withProperties(s.Props)
 	if err != nil {
 		return err
 	}
-	s.registerInvoker()
+	s.c = c
+	probeTimeout := s.c.Timeout
+	if probeTimeout == 0 {
+		probeTimeout = DefaultProbeTimeout
+	}
+	s.client = resty.New().SetHeaders(s.c.Headers).SetTimeout(probeTimeout)
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.probes = make(map[string]*probe)
+	if err := s.registerInvoker(); err != nil {
+		s.cancel()
+		return err
+	}
+	return nil
+}
 
+// Close stops the discovery watches, the invokers keep the servers they
+// last discovered.
+func (s *iocClient) Close() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.watches.Wait()
 	return nil
 }
 
-func (s *iocClient) registerServers() error {
-	for _, server := range s.c.Servers {
-		var baseUrl = server.Addr + server.RoutePrefix
-		var metas = make([]*dto.ServerInfo, 0)
-		startTime := time.Now()
-		_, err := s.client.R().
-			SetResult(&metas).
-			Get(baseUrl + constant.RouteMeta)
-		if err != nil {
+func (s *iocClient) discovery() discovery.Discovery {
+	if s.c.Discovery != nil {
+		return s.c.Discovery
+	}
+	return discovery.Static(lo.Map(s.c.Servers, func(server ServerConfig, _ int) *discovery.Instance {
+		return &discovery.Instance{
+			Addr:        server.Addr,
+			RoutePrefix: server.RoutePrefix,
+			Namespace:   server.Namespace,
+			Group:       server.Group,
+		}
+	})...)
+}
+
+func (s *iocClient) registerInvoker() error {
+	metas := s.r.GetComponents(registry.Interface(new(defination.InvokeComponent)))
+	s.invokers = make(map[string]*clientComponent)
+	var byService = make(map[string][]*clientComponent)
+	for _, m := range metas {
+		ic := m.Raw.(defination.InvokeComponent)
+		scope := dto.ServiceKey{ServiceId: ic.RemoteServiceId()}
+		if v, ok := m.Raw.(defination.RemoteNamespace); ok {
+			scope.Namespace = v.RemoteServiceNamespace()
+		}
+		if v, ok := m.Raw.(defination.RemoteGroup); ok {
+			scope.Group = v.RemoteServiceGroup()
+		}
+		var constraint string
+		if v, ok := m.Raw.(defination.InvokeVersion); ok {
+			constraint = v.RemoteVersionConstraint()
+		}
+		var lb = s.c.LoadBalance
+		if lb == nil {
+			lb = defaultLoadBalancing()
+		}
+		c := &clientComponent{
+			m:          m,
+			lb:         lb,
+			balance:    &s.balance,
+			scope:      scope,
+			constraint: constraint,
+			httpClient: resty.New().SetDebug(lo.FromPtr(s.c.Debug)).SetTimeout(s.c.Timeout).SetHeaders(s.c.Headers),
+			sFilters:   s.c.SerializationFilters,
+			dsFilters:  s.c.DeserializationFilters,
+			validate:   lo.FromPtr(s.c.Validate),
+			retry:      s.c.Retry,
+			limit:      s.c.Concurrency,
+		}
+		c.servers.Store(&[]*ServerInfo{})
+		byService[scope.ServiceId] = append(byService[scope.ServiceId], c)
+	}
+	for serviceId, cs := range byService {
+		if err := s.watch(serviceId, cs); err != nil {
+			return err
+		}
+		for _, c := range cs {
+			c.m.Raw.(defination.InvokeComponent).RegisterInvoker(c.invoke)
+			s.invokers[c.key.String()] = c
+		}
+	}
+	return nil
+}
+
+// watch binds the invokers of a service to its current instances and keeps
+// their servers up to date with the discovered ones.
+func (s *iocClient) watch(serviceId string, cs []*clientComponent) error {
+	ch, err := s.discovery().Watch(s.ctx, serviceId)
+	if err != nil {
+		return fmt.Errorf("discover %s: %v", serviceId, err)
+	}
+	services, err := s.resolve(serviceId, <-ch, true)
+	if err != nil {
+		return err
+	}
+	for _, c := range cs {
+		if err := c.bind(services); err != nil {
 			return err
 		}
-		si := &ServerInfo{
-			Addr:  baseUrl,
-			Delay: time.Now().Sub(startTime),
+	}
+	s.watches.Add(1)
+	go func() {
+		defer s.watches.Done()
+		for instances := range ch {
+			services, _ := s.resolve(serviceId, instances, false)
+			for _, c := range cs {
+				c.update(services)
+			}
 		}
-		for _, info := range metas {
-			if sm, ok := s.servers[info.ServiceId]; ok {
-				if !reflect.DeepEqual(sm.meta, info) {
-					return fmt.Errorf("remote component %s not equal in multi-server", info.ServiceId)
+	}()
+	return nil
+}
+
+// resolve probes the instances for the versions of the service they export,
+// grouped by the unversioned service key. While initializing the probes are
+// cached and fail the resolution, later ones are fresh and failed instances
+// are left out.
+func (s *iocClient) resolve(serviceId string, instances []*discovery.Instance, initializing bool) (map[dto.ServiceKey]map[string]*serverMeta, error) {
+	var services = make(map[dto.ServiceKey]map[string]*serverMeta)
+	for _, instance := range instances {
+		p, err := s.probe(instance, initializing)
+		if err != nil {
+			if initializing {
+				return nil, err
+			}
+			log.Printf("[remote-ioc] leave out instance %s of %s: %v", instance.URL(), serviceId, err)
+			continue
+		}
+		for _, info := range p.meta {
+			if info.ServiceId != serviceId ||
+				(instance.Namespace != "" && info.Namespace != instance.Namespace) ||
+				(instance.Group != "" && info.Group != instance.Group) {
+				continue
+			}
+			scope := info.ServiceKey
+			scope.Version = ""
+			versions, ok := services[scope]
+			if !ok {
+				versions = make(map[string]*serverMeta)
+				services[scope] = versions
+			}
+			if sm, ok := versions[info.Version]; ok {
+				if !reflect.DeepEqual(sm.meta.Methods, info.Methods) {
+					err = fmt.Errorf("remote component %s exports other methods on %s", info.ServiceKey, instance.URL())
+					if initializing {
+						return nil, err
+					}
+					log.Printf("[remote-ioc] leave out instance %s of %s: %v", instance.URL(), serviceId, err)
+					continue
 				}
-				sm.serverInfo = append(sm.serverInfo, si)
+				sm.serverInfo = append(sm.serverInfo, p.server)
 			} else {
-				s.servers[info.ServiceId] = &serverMeta{
+				versions[info.Version] = &serverMeta{
 					meta:       info,
-					serverInfo: []*ServerInfo{si},
+					serverInfo: []*ServerInfo{p.server},
 				}
 			}
 		}
 	}
-	return nil
+	return services, nil
 }
 
-func (s *iocClient) registerInvoker() {
-	metas := s.r.GetComponents(registry.Interface(new(defination.InvokeComponent)))
-	s.invokers = lo.SliceToMap(metas, func(m *meta.Meta) (string, *clientComponent) {
-		ic := m.Raw.(defination.InvokeComponent)
-		serviceId := ic.RemoteServiceId()
-		var lb = s.c.LoadBalance
-		if lb == nil {
-			lb = defaultLoadBalancing()
+type probe struct {
+	server *ServerInfo
+	meta   []*dto.ServerInfo
+}
+
+// probe reads the services and codecs of an instance from its meta route.
+func (s *iocClient) probe(instance *discovery.Instance, cached bool) (*probe, error) {
+	baseUrl := instance.URL()
+	if cached {
+		s.mu.Lock()
+		p, ok := s.probes[baseUrl]
+		s.mu.Unlock()
+		if ok {
+			return p, nil
 		}
-		sm := s.servers[serviceId]
-		var methodMap = make(map[string]reflect.Method)
-		for _, methodName := range sm.meta.Methods {
-			if method, ok := m.Type.MethodByName(methodName); ok {
-				methodMap[methodName] = method
-			} else {
-				panic(fmt.Errorf("remote component %s method %s not found", sm.meta.ServiceId, methodName))
-			}
+	}
+	var preference = s.c.Codecs
+	if len(preference) == 0 {
+		preference = codec.DefaultPreference()
+	}
+	var metas []*dto.ServerInfo
+	startTime := time.Now()
+	response, err := s.client.R().
+		SetContext(s.ctx).
+		SetResult(&metas).
+		Get(baseUrl + constant.RouteMeta)
+	if err != nil {
+		return nil, err
+	}
+	if response.IsError() {
+		return nil, fmt.Errorf("remote server %s meta: %s", baseUrl, response.Status())
+	}
+	var supported = []string{codec.JSON}
+	if h := response.Header().Get(constant.HeaderCodecs); h != "" {
+		supported = strings.Split(h, ",")
+	}
+	cc, ok := codec.Negotiate(preference, supported)
+	if !ok {
+		return nil, fmt.Errorf("remote server %s supports none of the codecs %v", baseUrl, preference)
+	}
+	p := &probe{
+		server: &ServerInfo{
+			Addr:     baseUrl,
+			Delay:    time.Now().Sub(startTime),
+			Instance: instance,
+			codec:    cc,
+			replays:  response.Header().Get(constant.HeaderIdempotency) == "true",
+		},
+		meta: metas,
+	}
+	if cached {
+		s.mu.Lock()
+		s.probes[baseUrl] = p
+		s.mu.Unlock()
+	}
+	return p, nil
+}
+
+// bind selects the version the invoker calls among the exported ones.
+func (i *clientComponent) bind(services map[dto.ServiceKey]map[string]*serverMeta) error {
+	sm, err := selectVersion(services[i.scope], i.constraint)
+	if err != nil {
+		return fmt.Errorf("remote component %s: %v", i.scope, err)
+	}
+	var methodMap = make(map[string]reflect.Method)
+	for _, methodName := range sm.meta.Methods {
+		if method, ok := i.m.Type.MethodByName(methodName); ok {
+			methodMap[methodName] = method
+		} else {
+			return fmt.Errorf("remote component %s method %s not found", sm.meta.ServiceId, methodName)
 		}
-		c := &clientComponent{
-			m:               m,
-			methodMap:       methodMap,
-			lb:              lb,
-			servers:         sm.serverInfo,
-			remoteServiceId: serviceId,
-			httpClient:      resty.New().SetDebug(s.c.Debug),
-			sFilters:        s.c.SerializationFilters,
-			dsFilters:       s.c.DeserializationFilters,
-		}
-		ic.RegisterInvoker(c.invoke)
-		return serviceId, c
+	}
+	i.methodMap = methodMap
+	i.key = sm.meta.ServiceKey
+	i.servers.Store(&sm.serverInfo)
+	return nil
+}
+
+// update replaces the servers of the bound version at once. Servers that
+// stay keep their state, removed ones are drained: the calls running on them
+// complete while new calls go to the remaining servers.
+func (i *clientComponent) update(services map[dto.ServiceKey]map[string]*serverMeta) {
+	var servers []*ServerInfo
+	if sm, ok := services[i.scope][i.key.Version]; ok {
+		servers = append(servers, sm.serverInfo...)
+	}
+	removed := lo.KeyBy(*i.servers.Load(), func(server *ServerInfo) string {
+		return server.Addr
 	})
+	for k, server := range servers {
+		if old, ok := removed[server.Addr]; ok && reflect.DeepEqual(old.Instance, server.Instance) {
+			servers[k] = old
+		}
+		delete(removed, server.Addr)
+	}
+	i.servers.Store(&servers)
+	for _, server := range removed {
+		log.Printf("[remote-ioc] remote component %s leaves %s, %d calls on it are drained", i.key, server.Addr, server.Inflight())
+	}
+	if len(servers) == 0 {
+		log.Printf("[remote-ioc] no instance of remote component %s is left", i.key)
+	}
+}
+
+// selectVersion picks the highest version matching the constraint. Without
+// a constraint the unversioned service is preferred when it is exported.
+func selectVersion(versions map[string]*serverMeta, constraint string) (*serverMeta, error) {
+	if len(versions) == 0 {
+		return nil, errors.New("no server exports it")
+	}
+	if sm, ok := versions[""]; ok && constraint == "" {
+		return sm, nil
+	}
+	c, err := semver.ParseConstraint(constraint)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		selected *serverMeta
+		highest  semver.Version
+	)
+	for version, sm := range versions {
+		v, err := semver.Parse(version)
+		if err != nil || !c.Check(v) {
+			continue
+		}
+		if selected == nil || v.Compare(highest) > 0 {
+			selected, highest = sm, v
+		}
+	}
+	if selected == nil {
+		return nil, fmt.Errorf("no exported version matches %q, exported %v", constraint, lo.Keys(versions))
+	}
+	return selected, nil
 }
 
 type clientComponent struct {
-	m               *meta.Meta
-	methodMap       map[string]reflect.Method
-	lb              LoadBalancing
-	servers         []*ServerInfo
-	remoteServiceId string
-	httpClient      *resty.Client
-	sFilters        []SerializationFilter
-	dsFilters       []DeserializationFilter
-}
-
-func (i *clientComponent) invoke(methodName string, v ...any) (results []any, err error) {
-	server := i.servers[i.lb(i.servers)]
+	m          *meta.Meta
+	methodMap  map[string]reflect.Method
+	lb         LoadBalancing
+	balance    *sync.Mutex
+	servers    atomic.Pointer[[]*ServerInfo]
+	scope      dto.ServiceKey
+	constraint string
+	key        dto.ServiceKey
+	httpClient *resty.Client
+	sFilters   []SerializationFilter
+	dsFilters  []DeserializationFilter
+	validate   bool
+	retry      RetryPolicy
+	limit      ConcurrencyLimit
+}
+
+func (i *clientComponent) invoke(methodName string, v ...any) ([]any, error) {
+	ctx := callContext(i.methodMap[methodName], v)
+	key := idempotencyKey(ctx)
+	for attempt := 0; ; attempt++ {
+		results, err := i.call(methodName, key, v...)
+		wait, ok := i.retry.wait(attempt, err)
+		if !ok || ctx.Err() != nil {
+			return results, err
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return results, err
+		}
+	}
+}
+
+// call makes one attempt of an invocation, the attempts of an invocation
+// share its idempotency key.
+func (i *clientComponent) call(methodName, key string, v ...any) (results []any, err error) {
 	method := i.methodMap[methodName]
 	results = make([]any, method.Type.NumOut())
 	for i := 0; i < method.Type.NumOut(); i++ {
 		results[i] = reflect.New(method.Type.Out(i)).Elem().Interface()
 	}
+	servers := *i.servers.Load()
+	if len(servers) == 0 {
+		return results, &InvokeError{
+			Kind:      KindTransport,
+			ServiceId: i.key.ServiceId,
+			Method:    methodName,
+			Err:       errNoServer,
+		}
+	}
+	i.balance.Lock()
+	server := servers[i.lb(servers)]
+	i.balance.Unlock()
+	server.inflight.Add(1)
+	defer server.inflight.Add(-1)
+	fail := func(kind ErrorKind, statusCode int, cause error) error {
+		return &InvokeError{
+			Kind:       kind,
+			ServiceId:  i.key.ServiceId,
+			Method:     methodName,
+			Addr:       server.Addr,
+			StatusCode: statusCode,
+			Err:        cause,
+			replays:    server.replays,
+		}
+	}
 
+	if i.validate {
+		if err = validateArgs(method, v); err != nil {
+			err = fail(KindValidation, 0, err)
+			return
+		}
+	}
 	var body *dto.Payload
 	body, err = i.buildBodyParam(method, v)
 	if err != nil {
+		err = fail(KindConversion, 0, err)
 		return
 	}
-	var resp = &dto.Payload{}
+	var data []byte
+	data, err = server.codec.Marshal(body)
+	if err != nil {
+		err = fail(KindConversion, 0, err)
+		return
+	}
+	var (
+		ctx    = callContext(method, v)
+		callId = newCallId()
+	)
+	if i.limit.enabled() {
+		limit := server.adaptiveLimit(i.key.ServiceId, i.limit)
+		if !limit.acquire() {
+			err = fail(KindLimited, 0, errLimited)
+			return
+		}
+		start := time.Now()
+		defer func() {
+			limit.release(time.Since(start), err)
+		}()
+	}
 	start := time.Now()
-	_, err = i.httpClient.
+	response, err := i.httpClient.
 		R().
-		SetBody(body).
-		SetResult(resp).
-		Post(server.Addr + fmt.Sprintf(constant.RouteMethod, i.remoteServiceId, methodName))
+		SetContext(ctx).
+		SetHeader("Content-Type", server.codec.ContentType()).
+		SetHeader("Accept", server.codec.ContentType()).
+		SetHeader(constant.HeaderCallId, callId).
+		SetHeader(constant.HeaderIdempotencyKey, key).
+		SetBody(data).
+		Post(server.Addr + i.key.Route(methodName))
 	if err != nil {
+		if ctx.Err() != nil {
+			// the dropped connection may not reach the server through proxies
+			go i.cancelCall(server, callId)
+		}
+		err = fail(transportKind(err), 0, err)
 		return
 	}
+	i.balance.Lock()
 	server.Delay = time.Now().Sub(start)
+	i.balance.Unlock()
+	if response.StatusCode() != http.StatusOK {
+		kind, cause := decodeErrorResponse(response)
+		err = fail(kind, response.StatusCode(), cause)
+		if kind == KindThrottled {
+			err.(*InvokeError).RetryAfter = retryAfter(response)
+		}
+		return
+	}
+
+	var resp = &dto.Payload{}
+	err = server.codec.Unmarshal(response.Body(), resp)
+	if err != nil {
+		err = fail(KindConversion, response.StatusCode(), err)
+		return
+	}
 
 	if len(resp.Params) != method.Type.NumOut() {
-		err = errors.New("remote server response parameters not equal")
+		err = fail(KindConversion, response.StatusCode(), errors.New("remote server response parameters not equal"))
 		return
 	}
 
@@ -147,6 +504,7 @@ func (i *clientComponent) invoke(methodName string, v ...any) (results []any, er
 		var value reflect.Value
 		value, err = transmission.DecryptParam(p, method.Type.Out(index), i.dsFilters)
 		if err != nil {
+			err = fail(KindConversion, response.StatusCode(), err)
 			return
 		}
 
@@ -155,6 +513,121 @@ func (i *clientComponent) invoke(methodName string, v ...any) (results []any, er
 	return
 }
 
+// validateArgs is the pre-flight form of the server side validation.
+func validateArgs(method reflect.Method, values []any) error {
+	for index := 1; index < method.Type.NumIn() && index <= len(values); index++ {
+		if method.Type.In(index) == contextType || values[index-1] == nil {
+			continue
+		}
+		if err := validation.Param(index, reflect.ValueOf(values[index-1])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// callContext returns the first context argument of the call, the
+// invocation is abandoned once it is done.
+func callContext(method reflect.Method, values []any) context.Context {
+	for index := 1; index < method.Type.NumIn() && index <= len(values); index++ {
+		if method.Type.In(index) != contextType {
+			continue
+		}
+		if ctx, ok := values[index-1].(context.Context); ok && ctx != nil {
+			return ctx
+		}
+	}
+	return context.Background()
+}
+
+type idempotencyKeyContext struct{}
+
+// WithIdempotencyKey sets the idempotency key of the invocations made with
+// ctx, so that calls the application repeats are deduplicated as the retries
+// of one call. Invocations get a key of their own otherwise.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContext{}, key)
+}
+
+func idempotencyKey(ctx context.Context) string {
+	if key, ok := ctx.Value(idempotencyKeyContext{}).(string); ok && key != "" {
+		return key
+	}
+	return newCallId()
+}
+
+func newCallId() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// cancelCall asks the server to cancel the context of an abandoned call.
+func (i *clientComponent) cancelCall(server *ServerInfo, callId string) {
+	_, err := i.httpClient.R().Delete(server.Addr + fmt.Sprintf(constant.RouteCall, callId))
+	if err != nil {
+		log.Printf("[remote-ioc] cancel call %s on %s failed: %v", callId, server.Addr, err)
+	}
+}
+
+func transportKind(err error) ErrorKind {
+	var netErr net.Error
+	if errors.Is(err, context.Canceled) {
+		return KindCanceled
+	}
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return KindTimeout
+	}
+	return KindTransport
+}
+
+// decodeErrorResponse turns a non-success response into the dto error of the
+// class the server marked it with.
+func decodeErrorResponse(response *resty.Response) (ErrorKind, error) {
+	var (
+		kind   = KindApplication
+		detail error
+	)
+	switch response.Header().Get(constant.HeaderErrorKind) {
+	case constant.ErrorKindValidate:
+		kind, detail = KindValidation, &dto.ValidateError{}
+	case constant.ErrorKindConvert:
+		kind, detail = KindConversion, &dto.ConvertError{}
+	case constant.ErrorKindPanic:
+		kind, detail = KindPanic, &dto.PanicError{}
+	case constant.ErrorKindThrottle:
+		kind = KindThrottled
+	case constant.ErrorKindOverload:
+		kind = KindOverloaded
+	case constant.ErrorKindUnavailable:
+		kind = KindUnavailable
+	}
+	cc, ok := codec.ForContentType(response.Header().Get("Content-Type"))
+	if detail != nil && ok && cc.Unmarshal(response.Body(), detail) == nil {
+		return kind, detail
+	}
+	// echo reports its own errors as {"message": "..."}
+	var message struct {
+		Message string `json:"message"`
+	}
+	if ok && cc.Unmarshal(response.Body(), &message) == nil && message.Message != "" {
+		return kind, errors.New(message.Message)
+	}
+	return kind, errors.New(strings.TrimSpace(string(response.Body())))
+}
+
+// retryAfter reads the Retry-After header, in seconds or as an HTTP date.
+func retryAfter(response *resty.Response) time.Duration {
+	value := response.Header().Get("Retry-After")
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
+
 func (i *clientComponent) buildBodyParam(method reflect.Method, values []any) (*dto.Payload, error) {
 	var params []*dto.Param
 	for index := 1; index < method.Type.NumIn(); index++ {
@@ -169,6 +642,10 @@ func (i *clientComponent) buildBodyParam(method reflect.Method, values []any) (*
 	}, nil
 }
 
+var errNoServer = errors.New("no server instance available")
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
 type serverMeta struct {
 	serverInfo []*ServerInfo
 	meta       *dto.ServerInfo
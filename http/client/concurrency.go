@@ -0,0 +1,124 @@
+package client
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ConcurrencyLimit adapts the calls in flight to each server and service
+// with AIMD, protecting struggling servers before they shed load themselves.
+// The limit grows by one for every limit worth of calls completing while it
+// is in use, and is multiplied by Backoff when a call is dropped: it failed
+// in transport, timed out, was throttled or overloaded, or was slower than
+// Latency. Calls beyond the limit fail at once with KindLimited. The limiter
+// is off when Max is zero.
+type ConcurrencyLimit struct {
+	// Initial limit, Max when zero.
+	Initial int `mapstructure:"initial"`
+	// Min and Max bound the limit, Min is 1 when zero.
+	Min int `mapstructure:"min"`
+	Max int `mapstructure:"max"`
+	// Latency is the slowest call not counted as dropped, calls are not
+	// dropped for their latency when zero.
+	Latency time.Duration `mapstructure:"latency"`
+	// Backoff is the decrease factor in (0, 1), 0.9 when zero.
+	Backoff float64 `mapstructure:"backoff"`
+}
+
+func (c ConcurrencyLimit) enabled() bool {
+	return c.Max > 0
+}
+
+// withDefaults fills the options left zero.
+func (c ConcurrencyLimit) withDefaults() ConcurrencyLimit {
+	if c.Min <= 0 {
+		c.Min = 1
+	}
+	if c.Max < c.Min {
+		c.Max = c.Min
+	}
+	if c.Initial <= 0 || c.Initial > c.Max {
+		c.Initial = c.Max
+	}
+	if c.Initial < c.Min {
+		c.Initial = c.Min
+	}
+	if c.Backoff <= 0 || c.Backoff >= 1 {
+		c.Backoff = 0.9
+	}
+	return c
+}
+
+// errLimited is the cause of the calls the concurrency limit rejected.
+var errLimited = errors.New("too many calls in flight")
+
+// adaptiveLimit is the concurrency limit of a service on a server.
+type adaptiveLimit struct {
+	c ConcurrencyLimit
+
+	mu       sync.Mutex
+	limit    float64
+	inflight int
+	rejected atomic.Int64
+}
+
+func newAdaptiveLimit(c ConcurrencyLimit) *adaptiveLimit {
+	c = c.withDefaults()
+	return &adaptiveLimit{c: c, limit: float64(c.Initial)}
+}
+
+// acquire admits a call under the limit.
+func (l *adaptiveLimit) acquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inflight >= int(l.limit) {
+		l.rejected.Add(1)
+		return false
+	}
+	l.inflight++
+	return true
+}
+
+// release ends a call admitted by acquire and adapts the limit to its
+// outcome, the limit is kept when err says nothing about the server.
+func (l *adaptiveLimit) release(latency time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	inflight := l.inflight
+	l.inflight--
+	switch {
+	case dropped(err) || (l.c.Latency > 0 && latency > l.c.Latency):
+		l.limit = math.Max(float64(l.c.Min), l.limit*l.c.Backoff)
+	case err != nil && kindOf(err) == KindCanceled:
+	case float64(inflight*2) >= l.limit:
+		// an idle limit would grow without being tested
+		l.limit = math.Min(float64(l.c.Max), l.limit+1/l.limit)
+	}
+}
+
+// Limit is the number of calls currently allowed in flight.
+func (l *adaptiveLimit) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// dropped reports whether err signals a struggling server.
+func dropped(err error) bool {
+	switch kindOf(err) {
+	case KindTransport, KindTimeout, KindThrottled, KindOverloaded:
+		return true
+	}
+	return false
+}
+
+func kindOf(err error) ErrorKind {
+	var invokeErr *InvokeError
+	if errors.As(err, &invokeErr) {
+		return invokeErr.Kind
+	}
+	return 0
+}
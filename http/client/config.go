@@ -2,63 +2,168 @@ package client
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"github.com/go-kid/remote-ioc/http/codec"
+	"github.com/go-kid/remote-ioc/http/discovery"
 	"github.com/go-kid/remote-ioc/http/transmission"
-	"reflect"
+	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Config struct {
-	Servers                []ServerConfig
-	Debug                  bool
+	// Servers are the static instances used when Discovery is nil.
+	Servers   []ServerConfig
+	Discovery discovery.Discovery
+	// Debug logs the requests and responses. The booleans are pointers so
+	// that an explicit false is not taken from the properties, nil leaves
+	// them to those.
+	Debug *bool
+	// Timeout bounds every invocation, zero means no timeout. It bounds the
+	// probes of the servers too, DefaultProbeTimeout when zero.
+	Timeout                time.Duration
 	LoadBalance            LoadBalancing
 	SerializationFilters   []SerializationFilter
 	DeserializationFilters []DeserializationFilter
+	// Codecs is the codec preference, the first one a server supports is
+	// used with it. codec.DefaultPreference is used when empty.
+	Codecs []string
+	// Validate checks the arguments against their validate tags before
+	// sending them, with the rules the server applies.
+	Validate *bool
+	// Headers are sent with every request, such as the credentials of
+	// servers that authenticate callers.
+	Headers map[string]string
+	// Retry retries the calls servers turned away, none when zero.
+	Retry RetryPolicy
+	// Concurrency adapts the calls in flight to each server, no limit when
+	// zero.
+	Concurrency ConcurrencyLimit
+}
+
+// DefaultProbeTimeout bounds the probes of the servers when Timeout is zero.
+const DefaultProbeTimeout = 10 * time.Second
+
+// RetryPolicy retries throttled, overloaded or unavailable calls, which did
+// not run on the server. Calls whose response was lost, by a transport
+// failure, a timeout or a 502 or 504 of a gateway, are retried too when the
+// server stores responses: the retry is answered with the stored one if the
+// call ran. The Retry-After the server asked for is waited, or Backoff
+// doubled on each retry when there is none.
+type RetryPolicy struct {
+	// Attempts is the number of retries after the first call.
+	Attempts int `mapstructure:"attempts"`
+	// Backoff is the first wait without a Retry-After, 100ms when zero.
+	Backoff time.Duration `mapstructure:"backoff"`
+	// MaxWait fails the calls asked to wait longer at once, no bound when
+	// zero.
+	MaxWait time.Duration `mapstructure:"max-wait"`
+}
+
+// wait returns how long to wait before retrying a failed attempt.
+func (p RetryPolicy) wait(attempt int, err error) (time.Duration, bool) {
+	var invokeErr *InvokeError
+	if attempt >= p.Attempts || !errors.As(err, &invokeErr) {
+		return 0, false
+	}
+	switch invokeErr.Kind {
+	case KindThrottled, KindOverloaded, KindUnavailable:
+	case KindTransport, KindTimeout:
+		if !invokeErr.replays {
+			return 0, false
+		}
+	case KindApplication:
+		if !invokeErr.replays ||
+			(invokeErr.StatusCode != http.StatusBadGateway && invokeErr.StatusCode != http.StatusGatewayTimeout) {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	wait := invokeErr.RetryAfter
+	if wait <= 0 {
+		backoff := p.Backoff
+		if backoff <= 0 {
+			backoff = 100 * time.Millisecond
+		}
+		wait = backoff << attempt
+	}
+	if p.MaxWait > 0 && wait > p.MaxWait {
+		return 0, false
+	}
+	return wait, true
 }
 
 type ServerConfig struct {
-	Addr        string
-	RoutePrefix string
+	Addr        string `mapstructure:"addr"`
+	RoutePrefix string `mapstructure:"route-prefix"`
+	// Namespace and Group restrict the services used from the server to the
+	// ones placed in them, empty ones do not restrict.
+	Namespace string `mapstructure:"namespace"`
+	Group     string `mapstructure:"group"`
 }
 
 type ServerInfo struct {
-	Addr  string
+	Addr string
+	// Delay is how long the last call to the server took to be answered. It
+	// is only written while no LoadBalancing runs.
 	Delay time.Duration
+	// Instance is the discovered instance, with its zone and weight.
+	Instance *discovery.Instance
+	codec    codec.Codec
+	// replays tells whether the server replays stored responses to the
+	// calls sent again with their idempotency key.
+	replays  bool
+	inflight atomic.Int64
+	// limits are the adaptive concurrency limits by service id
+	limits sync.Map
+}
+
+// Inflight is the number of calls running on the server.
+func (s *ServerInfo) Inflight() int64 {
+	return s.inflight.Load()
+}
+
+// ConcurrencyLimit is the number of calls to the service allowed in flight
+// on the server, zero when it is not limited.
+func (s *ServerInfo) ConcurrencyLimit(serviceId string) int {
+	if l, ok := s.limits.Load(serviceId); ok {
+		return l.(*adaptiveLimit).Limit()
+	}
+	return 0
+}
+
+func (s *ServerInfo) adaptiveLimit(serviceId string, c ConcurrencyLimit) *adaptiveLimit {
+	if l, ok := s.limits.Load(serviceId); ok {
+		return l.(*adaptiveLimit)
+	}
+	l, _ := s.limits.LoadOrStore(serviceId, newAdaptiveLimit(c))
+	return l.(*adaptiveLimit)
 }
 
 type LoadBalancing func(servers []*ServerInfo) int
 
 var defaultLoadBalancing = func() LoadBalancing {
-	var last int
+	var last atomic.Uint64
 	return func(servers []*ServerInfo) int {
-		last++
-		if last == len(servers) {
-			last = 0
-		}
-		return last
+		return int(last.Add(1) % uint64(len(servers)))
 	}
 }
 
 type SerializationFilter = transmission.SerializationFilter
 type DeserializationFilter = transmission.DeserializationFilter
 
+// CtxToMapFilter sends the values of the registered context keys.
+//
+// Deprecated: context.Context parameters are propagated without a filter,
+// register the keys to propagate with transmission.NewContextKey or
+// transmission.RegisterContextKey.
 var CtxToMapFilter SerializationFilter = func(inType string, value any) (value2 any, ok bool, err error) {
 	ok = inType == "context.Context"
 	if ok {
-		value2 = getPairs(value.(context.Context))
+		ctx, _ := value.(context.Context)
+		value2, err = transmission.EncodeContext(ctx)
 	}
 	return
 }
-
-func getPairs(ctx context.Context) map[string]any {
-	var m = make(map[string]any)
-	for v := reflect.ValueOf(ctx).Elem(); v.Type().String() != "context.emptyCtx"; v = v.FieldByName("Context").Elem().Elem() {
-		if v.Type().String() == "context.valueCtx" {
-			if v.FieldByName("key").Elem().Kind() == reflect.String {
-				key := fmt.Sprintf("%v", v.FieldByName("key"))
-				m[key] = fmt.Sprintf("%v", v.FieldByName("val"))
-			}
-		}
-	}
-	return m
-}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrorKind classifies why an invocation failed.
+type ErrorKind int
+
+const (
+	// KindTransport means no response was received from the server.
+	KindTransport ErrorKind = iota + 1
+	// KindTimeout means the request did not complete in time.
+	KindTimeout
+	// KindValidation means the server rejected the parameter kinds.
+	KindValidation
+	// KindConversion means parameters or results could not be converted.
+	KindConversion
+	// KindApplication means the server failed the request in any other way.
+	KindApplication
+	// KindPanic means the remote method panicked.
+	KindPanic
+	// KindCanceled means the caller's context was cancelled.
+	KindCanceled
+	// KindThrottled means the server rate limited the call, it did not run.
+	KindThrottled
+	// KindOverloaded means the bulkhead of the method was saturated, the
+	// call did not run.
+	KindOverloaded
+	// KindLimited means the client's concurrency limit of the server was
+	// reached, the call was not sent.
+	KindLimited
+	// KindUnavailable means the method was disabled or the server was in
+	// maintenance, the call did not run.
+	KindUnavailable
+)
+
+var (
+	ErrTransport   = errors.New("remote invoke transport failure")
+	ErrTimeout     = errors.New("remote invoke timeout")
+	ErrValidation  = errors.New("remote invoke validation failure")
+	ErrConversion  = errors.New("remote invoke conversion failure")
+	ErrApplication = errors.New("remote invoke application failure")
+	ErrPanic       = errors.New("remote invoke panic")
+	ErrCanceled    = errors.New("remote invoke canceled")
+	ErrThrottled   = errors.New("remote invoke throttled")
+	ErrOverloaded  = errors.New("remote invoke overloaded")
+	ErrLimited     = errors.New("remote invoke concurrency limited")
+	ErrUnavailable = errors.New("remote invoke unavailable")
+)
+
+var kindErrors = map[ErrorKind]error{
+	KindTransport:   ErrTransport,
+	KindTimeout:     ErrTimeout,
+	KindValidation:  ErrValidation,
+	KindConversion:  ErrConversion,
+	KindApplication: ErrApplication,
+	KindPanic:       ErrPanic,
+	KindCanceled:    ErrCanceled,
+	KindThrottled:   ErrThrottled,
+	KindOverloaded:  ErrOverloaded,
+	KindLimited:     ErrLimited,
+	KindUnavailable: ErrUnavailable,
+}
+
+// InvokeError is returned when an invocation fails before the remote method
+// could return. errors.Is matches it against the Err* value of its kind and
+// it unwraps to the detailed cause, such as *dto.ValidateError,
+// *dto.ConvertError or *dto.PanicError.
+type InvokeError struct {
+	Kind      ErrorKind
+	ServiceId string
+	Method    string
+	Addr      string
+	// StatusCode is the HTTP status of the response, zero if there was none.
+	StatusCode int
+	// RetryAfter is how long a throttling server asked to wait.
+	RetryAfter time.Duration
+	Err        error
+	// replays tells whether the server replays the response of the call to
+	// a retry.
+	replays bool
+}
+
+func (e *InvokeError) Error() string {
+	msg := fmt.Sprintf("%s: %s.%s on %s", kindErrors[e.Kind], e.ServiceId, e.Method, e.Addr)
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" status %d", e.StatusCode)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *InvokeError) Unwrap() error {
+	return e.Err
+}
+
+func (e *InvokeError) Is(target error) bool {
+	return kindErrors[e.Kind] == target
+}
@@ -1,6 +1,10 @@
 package client
 
-import "github.com/go-kid/ioc/app"
+import (
+	"errors"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/ioc/registry"
+)
 
 func Remote(c Config) app.SettingOption {
 	return func(s *app.App) {
@@ -10,3 +14,20 @@ func Remote(c Config) app.SettingOption {
 		})
 	}
 }
+
+// Close stops the discovery watches of the remote clients of an app.
+func Close(a *app.App) error {
+	var errs []error
+	for _, m := range a.Registry.GetComponents(registry.Interface(new(closer))) {
+		if c, ok := m.Raw.(*iocClient); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type closer interface {
+	Close() error
+}
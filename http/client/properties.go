@@ -0,0 +1,167 @@
+package client
+
+import (
+	"fmt"
+	"github.com/go-kid/remote-ioc/http/codec"
+	"github.com/go-kid/remote-ioc/http/discovery"
+	"github.com/go-kid/remote-ioc/http/naming"
+	"github.com/go-kid/remote-ioc/http/props"
+	"github.com/samber/lo"
+	"net/url"
+	"time"
+)
+
+// PropertiesKey is the configuration key of the client properties.
+const PropertiesKey = props.Prefix + ".client"
+
+// Properties configure the client from the configuration files of the app,
+// such as
+//
+//	remote-ioc:
+//	  client:
+//	    timeout: 3s
+//	    servers:
+//	      - addr: http://orders:8080
+//
+// and from environment variables overriding them, see props.Env. The options
+// of Config given in code take precedence over both, a Discovery or Servers
+// given in code replace the ones configured.
+type Properties struct {
+	// Servers are the static instances, used when no discovery is set.
+	Servers   []ServerConfig      `mapstructure:"servers"`
+	Discovery DiscoveryProperties `mapstructure:"discovery"`
+	Debug     bool                `mapstructure:"debug"`
+	// Timeout bounds every invocation, no timeout when zero.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// Codecs is the codec preference, msgpack, cbor then json when empty.
+	Codecs   []string          `mapstructure:"codecs"`
+	Validate bool              `mapstructure:"validate"`
+	Headers  map[string]string `mapstructure:"headers"`
+	Retry    RetryPolicy       `mapstructure:"retry"`
+	// Concurrency adapts the calls in flight to each server, no limit when
+	// max is zero.
+	Concurrency ConcurrencyLimit `mapstructure:"concurrency"`
+}
+
+// DiscoveryProperties select at most one discovery of the instances.
+type DiscoveryProperties struct {
+	// File lists the instances of each service id, see discovery.File.
+	File string `mapstructure:"file"`
+	// Registry is the base URL of an embedded registry, Headers are sent to
+	// it.
+	Registry string            `mapstructure:"registry"`
+	Headers  map[string]string `mapstructure:"headers"`
+	DNS      DNSProperties     `mapstructure:"dns"`
+}
+
+// DNSProperties discover instances from SRV records, see discovery.DNS.
+type DNSProperties struct {
+	Domain string `mapstructure:"domain"`
+	// Proto is tcp when empty.
+	Proto string `mapstructure:"proto"`
+	// Scheme is http when empty.
+	Scheme string `mapstructure:"scheme"`
+	// Refresh is the lookup interval, 30s when zero.
+	Refresh time.Duration `mapstructure:"refresh"`
+}
+
+// withProperties fills the options left zero in code from p and the
+// environment, then checks the result.
+func (c Config) withProperties(p *Properties) (Config, error) {
+	if p == nil {
+		p = &Properties{}
+	}
+	if err := props.Env(PropertiesKey, p); err != nil {
+		return c, err
+	}
+	if c.Discovery == nil && len(c.Servers) == 0 {
+		d, err := p.Discovery.discovery()
+		if err != nil {
+			return c, err
+		}
+		c.Discovery = d
+		c.Servers = p.Servers
+	}
+	if c.Debug == nil {
+		c.Debug = lo.ToPtr(p.Debug)
+	}
+	if c.Timeout == 0 {
+		c.Timeout = p.Timeout
+	}
+	if len(c.Codecs) == 0 {
+		c.Codecs = p.Codecs
+	}
+	if c.Validate == nil {
+		c.Validate = lo.ToPtr(p.Validate)
+	}
+	if c.Headers == nil {
+		c.Headers = p.Headers
+	}
+	if c.Retry == (RetryPolicy{}) {
+		c.Retry = p.Retry
+	}
+	if c.Concurrency == (ConcurrencyLimit{}) {
+		c.Concurrency = p.Concurrency
+	}
+	return c, c.check()
+}
+
+func (p DiscoveryProperties) discovery() (discovery.Discovery, error) {
+	var set []string
+	for i, value := range []string{p.File, p.Registry, p.DNS.Domain} {
+		if value != "" {
+			set = append(set, []string{"file", "registry", "dns"}[i])
+		}
+	}
+	if len(set) > 1 {
+		return nil, fmt.Errorf("%s.discovery: set one of file, registry and dns, not %v", PropertiesKey, set)
+	}
+	switch {
+	case p.File != "":
+		return discovery.File(p.File), nil
+	case p.Registry != "":
+		if _, err := url.ParseRequestURI(p.Registry); err != nil {
+			return nil, fmt.Errorf("%s.discovery.registry: invalid URL %q", PropertiesKey, p.Registry)
+		}
+		return naming.NewClient(naming.ClientConfig{Addr: p.Registry, Headers: p.Headers}), nil
+	case p.DNS.Domain != "":
+		return discovery.DNS(discovery.DNSConfig{
+			Domain:  p.DNS.Domain,
+			Proto:   p.DNS.Proto,
+			Scheme:  p.DNS.Scheme,
+			Refresh: p.DNS.Refresh,
+		}), nil
+	}
+	return nil, nil
+}
+
+// check rejects invalid options by their property keys.
+func (c Config) check() error {
+	for i, server := range c.Servers {
+		if u, err := url.Parse(server.Addr); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%s.servers[%d].addr: invalid URL %q", PropertiesKey, i, server.Addr)
+		}
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("%s.timeout: negative timeout %s", PropertiesKey, c.Timeout)
+	}
+	if c.Retry.Attempts < 0 || c.Retry.Backoff < 0 || c.Retry.MaxWait < 0 {
+		return fmt.Errorf("%s.retry: negative attempts, backoff or max-wait", PropertiesKey)
+	}
+	l := c.Concurrency
+	if l.Initial < 0 || l.Min < 0 || l.Max < 0 || l.Latency < 0 {
+		return fmt.Errorf("%s.concurrency: negative initial, min, max or latency", PropertiesKey)
+	}
+	if l.Backoff < 0 || l.Backoff >= 1 {
+		return fmt.Errorf("%s.concurrency.backoff: %v is not in (0, 1)", PropertiesKey, l.Backoff)
+	}
+	if l.Max > 0 && l.Min > l.Max {
+		return fmt.Errorf("%s.concurrency: min %d above max %d", PropertiesKey, l.Min, l.Max)
+	}
+	for _, name := range c.Codecs {
+		if _, ok := codec.Get(name); !ok {
+			return fmt.Errorf("%s.codecs: unknown codec %q, registered are %v", PropertiesKey, name, codec.Names())
+		}
+	}
+	return nil
+}
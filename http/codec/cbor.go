@@ -0,0 +1,387 @@
+package codec
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+)
+
+type cborCodec struct{}
+
+func (cborCodec) Name() string {
+	return CBOR
+}
+
+func (cborCodec) ContentType() string {
+	return "application/cbor"
+}
+
+func (cborCodec) Marshal(v any) ([]byte, error) {
+	tree, err := toTree(v)
+	if err != nil {
+		return nil, err
+	}
+	var e cborEncoder
+	if err = e.encode(tree); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+func (cborCodec) Unmarshal(data []byte, v any) error {
+	d := &cborDecoder{data: data}
+	tree, err := d.decode()
+	if err != nil {
+		return err
+	}
+	if d.pos != len(d.data) {
+		return errors.New("cbor: trailing data")
+	}
+	return fromTree(tree, v)
+}
+
+const (
+	cborUint byte = iota << 5
+	cborNegInt
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+const (
+	cborTagPosBignum = 2
+	cborTagNegBignum = 3
+	cborIndefinite   = 31
+	cborBreak        = 0xff
+)
+
+type cborEncoder struct {
+	buf []byte
+}
+
+func (e *cborEncoder) writeHead(major byte, n uint64) {
+	switch {
+	case n < 24:
+		e.buf = append(e.buf, major|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, major|24, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, major|25)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	case n <= math.MaxUint32:
+		e.buf = append(e.buf, major|26)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	default:
+		e.buf = append(e.buf, major|27)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, n)
+	}
+}
+
+func (e *cborEncoder) encode(v any) error {
+	switch v := v.(type) {
+	case nil:
+		e.buf = append(e.buf, cborSimple|22)
+	case bool:
+		if v {
+			e.buf = append(e.buf, cborSimple|21)
+		} else {
+			e.buf = append(e.buf, cborSimple|20)
+		}
+	case json.Number:
+		return e.encodeNumber(v)
+	case string:
+		e.writeHead(cborText, uint64(len(v)))
+		e.buf = append(e.buf, v...)
+	case []byte:
+		e.writeHead(cborBytes, uint64(len(v)))
+		e.buf = append(e.buf, v...)
+	case []any:
+		e.writeHead(cborArray, uint64(len(v)))
+		for _, item := range v {
+			if err := e.encode(item); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		e.writeHead(cborMap, uint64(len(v)))
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			_ = e.encode(k)
+			if err := e.encode(v[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("cbor: unsupported value of type %T", v)
+	}
+	return nil
+}
+
+func (e *cborEncoder) encodeNumber(n json.Number) error {
+	num, err := parseNumber(n)
+	if err != nil {
+		return err
+	}
+	switch {
+	case num.big != nil, num.negative && num.u > 1<<63:
+		return fmt.Errorf("cbor: integer %s overflows 64 bits", n)
+	case !num.isInt:
+		e.buf = append(e.buf, cborSimple|27)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, math.Float64bits(num.f))
+	case num.negative:
+		e.writeHead(cborNegInt, num.u-1)
+	default:
+		e.writeHead(cborUint, num.u)
+	}
+	return nil
+}
+
+type cborDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+var errCborShort = errors.New("cbor: unexpected end of data")
+
+func (d *cborDecoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errCborShort
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// readHead returns the major type, the additional information and the
+// argument of the next data item head.
+func (d *cborDecoder) readHead() (major byte, info byte, arg uint64, err error) {
+	b, err := d.read(1)
+	if err != nil {
+		return
+	}
+	major, info = b[0]&0xe0, b[0]&0x1f
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info <= 27:
+		size := uint64(1) << (info - 24)
+		var raw []byte
+		raw, err = d.read(size)
+		if err != nil {
+			return
+		}
+		for _, c := range raw {
+			arg = arg<<8 | uint64(c)
+		}
+	case info == cborIndefinite:
+	default:
+		err = fmt.Errorf("cbor: invalid additional information %d", info)
+	}
+	return
+}
+
+func (d *cborDecoder) isBreak() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == cborBreak {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+func (d *cborDecoder) decode() (any, error) {
+	if d.depth++; d.depth > maxDepth {
+		return nil, fmt.Errorf("cbor: exceeded max depth %d", maxDepth)
+	}
+	defer func() { d.depth-- }()
+	major, info, arg, err := d.readHead()
+	if err != nil {
+		return nil, err
+	}
+	if info == cborIndefinite {
+		return d.decodeIndefinite(major)
+	}
+	switch major {
+	case cborUint:
+		return uintNumber(arg), nil
+	case cborNegInt:
+		if arg > math.MaxInt64 {
+			return nil, fmt.Errorf("cbor: integer -1-%d overflows 64 bits", arg)
+		}
+		return intNumber(-1 - int64(arg)), nil
+	case cborBytes:
+		raw, err := d.read(arg)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), raw...), nil
+	case cborText:
+		raw, err := d.read(arg)
+		if err != nil {
+			return nil, err
+		}
+		return string(raw), nil
+	case cborArray:
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, errCborShort
+		}
+		arr := make([]any, arg)
+		for i := range arr {
+			if arr[i], err = d.decode(); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	case cborMap:
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, errCborShort
+		}
+		m := make(map[string]any, arg)
+		for i := uint64(0); i < arg; i++ {
+			if err = d.decodePair(m); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	case cborTag:
+		return d.decodeTagged(arg)
+	default:
+		return d.decodeSimple(info, arg)
+	}
+}
+
+func (d *cborDecoder) decodeIndefinite(major byte) (any, error) {
+	switch major {
+	case cborBytes, cborText:
+		var buf []byte
+		for !d.isBreak() {
+			chunkMajor, info, arg, err := d.readHead()
+			if err != nil {
+				return nil, err
+			}
+			if chunkMajor != major || info == cborIndefinite {
+				return nil, errors.New("cbor: invalid indefinite length string chunk")
+			}
+			raw, err := d.read(arg)
+			if err != nil {
+				return nil, err
+			}
+			buf = append(buf, raw...)
+		}
+		if major == cborText {
+			return string(buf), nil
+		}
+		return buf, nil
+	case cborArray:
+		arr := make([]any, 0)
+		for !d.isBreak() {
+			v, err := d.decode()
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, nil
+	case cborMap:
+		m := make(map[string]any)
+		for !d.isBreak() {
+			if err := d.decodePair(m); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("cbor: major type %d can not have indefinite length", major>>5)
+}
+
+func (d *cborDecoder) decodePair(m map[string]any) error {
+	k, err := d.decode()
+	if err != nil {
+		return err
+	}
+	key, ok := k.(string)
+	if !ok {
+		return fmt.Errorf("cbor: map key of type %T is not a string", k)
+	}
+	v, err := d.decode()
+	if err != nil {
+		return err
+	}
+	m[key] = v
+	return nil
+}
+
+// decodeTagged understands bignums within 64 bits, the range MessagePack
+// holds too. Other tags are dropped and their content is decoded as is.
+func (d *cborDecoder) decodeTagged(tag uint64) (any, error) {
+	v, err := d.decode()
+	if err != nil {
+		return nil, err
+	}
+	if tag != cborTagPosBignum && tag != cborTagNegBignum {
+		return v, nil
+	}
+	raw, ok := v.([]byte)
+	if !ok {
+		return nil, errors.New("cbor: bignum content is not a byte string")
+	}
+	n := new(big.Int).SetBytes(raw)
+	if tag == cborTagNegBignum {
+		n.Neg(n).Sub(n, big.NewInt(1))
+	}
+	if !n.IsInt64() && !n.IsUint64() {
+		return nil, fmt.Errorf("cbor: integer %s overflows 64 bits", n)
+	}
+	return json.Number(n.String()), nil
+}
+
+func (d *cborDecoder) decodeSimple(info byte, arg uint64) (any, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return floatNumber(halfToFloat(uint16(arg)))
+	case 26:
+		return floatNumber(float64(math.Float32frombits(uint32(arg))))
+	case 27:
+		return floatNumber(math.Float64frombits(arg))
+	}
+	return nil, fmt.Errorf("cbor: unsupported simple value %d", arg)
+}
+
+func halfToFloat(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
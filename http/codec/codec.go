@@ -0,0 +1,103 @@
+package codec
+
+import (
+	"mime"
+	"strings"
+	"sync"
+)
+
+const (
+	JSON        = "json"
+	MessagePack = "msgpack"
+	CBOR        = "cbor"
+)
+
+// Codec marshals the wire envelope of remote invocations. Every codec
+// shares the JSON data model: objects, arrays, strings, numbers, booleans
+// and null, so the transmission layer sees the same shapes regardless of
+// the format that was negotiated. The binary codecs hold integers within
+// the range of int64 and uint64 and reject larger ones alike.
+type Codec interface {
+	Name() string
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	mu       sync.RWMutex
+	codecs   = make(map[string]Codec)
+	mimes    = make(map[string]Codec)
+	priority []string
+)
+
+func init() {
+	Register(jsonCodec{})
+	Register(msgpackCodec{})
+	Register(cborCodec{})
+}
+
+// Register adds a codec, replacing any codec registered under the same name.
+// Codecs registered earlier are preferred by DefaultPreference.
+func Register(c Codec) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := codecs[c.Name()]; !ok {
+		priority = append(priority, c.Name())
+	}
+	codecs[c.Name()] = c
+	mimes[c.ContentType()] = c
+}
+
+// Get returns the codec registered under name.
+func Get(name string) (Codec, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	c, ok := codecs[name]
+	return c, ok
+}
+
+// ForContentType returns the codec handling the media type of a Content-Type
+// header value, parameters are ignored. An empty value selects JSON.
+func ForContentType(contentType string) (Codec, bool) {
+	if contentType == "" {
+		return Get(JSON)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	c, ok := mimes[strings.ToLower(mediaType)]
+	return c, ok
+}
+
+// Names returns the names of all registered codecs in registration order.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]string(nil), priority...)
+}
+
+// DefaultPreference is the order a client tries codecs in when none is
+// configured: compact binary formats first, JSON last.
+func DefaultPreference() []string {
+	return []string{MessagePack, CBOR, JSON}
+}
+
+// Negotiate picks the first codec of preference that is both registered and
+// supported by the remote side.
+func Negotiate(preference, supported []string) (Codec, bool) {
+	for _, name := range preference {
+		for _, s := range supported {
+			if s != name {
+				continue
+			}
+			if c, ok := Get(name); ok {
+				return c, true
+			}
+		}
+	}
+	return nil, false
+}
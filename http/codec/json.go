@@ -0,0 +1,28 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string {
+	return JSON
+}
+
+func (jsonCodec) ContentType() string {
+	return "application/json"
+}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal keeps numbers as json.Number so that untyped values are not
+// forced through float64.
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
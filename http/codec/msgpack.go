@@ -0,0 +1,329 @@
+package codec
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string {
+	return MessagePack
+}
+
+func (msgpackCodec) ContentType() string {
+	return "application/msgpack"
+}
+
+func (msgpackCodec) Marshal(v any) ([]byte, error) {
+	tree, err := toTree(v)
+	if err != nil {
+		return nil, err
+	}
+	var e msgpackEncoder
+	if err = e.encode(tree); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v any) error {
+	d := &msgpackDecoder{data: data}
+	tree, err := d.decode()
+	if err != nil {
+		return err
+	}
+	if d.pos != len(d.data) {
+		return errors.New("msgpack: trailing data")
+	}
+	return fromTree(tree, v)
+}
+
+type msgpackEncoder struct {
+	buf []byte
+}
+
+func (e *msgpackEncoder) encode(v any) error {
+	switch v := v.(type) {
+	case nil:
+		e.buf = append(e.buf, 0xc0)
+	case bool:
+		if v {
+			e.buf = append(e.buf, 0xc3)
+		} else {
+			e.buf = append(e.buf, 0xc2)
+		}
+	case json.Number:
+		return e.encodeNumber(v)
+	case string:
+		e.writeLength(len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		e.buf = append(e.buf, v...)
+	case []byte:
+		e.writeLength(len(v), 0, 0, 0xc4, 0xc5, 0xc6)
+		e.buf = append(e.buf, v...)
+	case []any:
+		e.writeLength(len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range v {
+			if err := e.encode(item); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		e.writeLength(len(v), 0x80, 16, 0, 0xde, 0xdf)
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			_ = e.encode(k)
+			if err := e.encode(v[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported value of type %T", v)
+	}
+	return nil
+}
+
+func (e *msgpackEncoder) encodeNumber(n json.Number) error {
+	num, err := parseNumber(n)
+	if err != nil {
+		return err
+	}
+	switch {
+	case num.big != nil:
+		return fmt.Errorf("msgpack: integer %s overflows 64 bits", n)
+	case !num.isInt:
+		e.buf = append(e.buf, 0xcb)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, math.Float64bits(num.f))
+	case num.negative:
+		if num.u > 1<<63 {
+			return fmt.Errorf("msgpack: integer %s overflows 64 bits", n)
+		}
+		i := int64(-num.u)
+		switch {
+		case i >= -32:
+			e.buf = append(e.buf, byte(i))
+		case i >= math.MinInt8:
+			e.buf = append(e.buf, 0xd0, byte(i))
+		case i >= math.MinInt16:
+			e.buf = append(e.buf, 0xd1)
+			e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(i))
+		case i >= math.MinInt32:
+			e.buf = append(e.buf, 0xd2)
+			e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(i))
+		default:
+			e.buf = append(e.buf, 0xd3)
+			e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(i))
+		}
+	default:
+		u := num.u
+		switch {
+		case u <= 0x7f:
+			e.buf = append(e.buf, byte(u))
+		case u <= math.MaxUint8:
+			e.buf = append(e.buf, 0xcc, byte(u))
+		case u <= math.MaxUint16:
+			e.buf = append(e.buf, 0xcd)
+			e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(u))
+		case u <= math.MaxUint32:
+			e.buf = append(e.buf, 0xce)
+			e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(u))
+		default:
+			e.buf = append(e.buf, 0xcf)
+			e.buf = binary.BigEndian.AppendUint64(e.buf, u)
+		}
+	}
+	return nil
+}
+
+// writeLength writes a length header. fix is the fix-format prefix used for
+// lengths below fixMax (a zero fixMax disables it), the others are the
+// prefixes of the 8, 16 and 32 bit forms (a zero p8 disables it).
+func (e *msgpackEncoder) writeLength(n int, fix byte, fixMax int, p8, p16, p32 byte) {
+	switch {
+	case n < fixMax:
+		e.buf = append(e.buf, fix|byte(n))
+	case p8 != 0 && n <= math.MaxUint8:
+		e.buf = append(e.buf, p8, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, p16)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, p32)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+}
+
+type msgpackDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+var errMsgpackShort = errors.New("msgpack: unexpected end of data")
+
+func (d *msgpackDecoder) read(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, errMsgpackShort
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *msgpackDecoder) readUint(size int) (uint64, error) {
+	b, err := d.read(size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+func (d *msgpackDecoder) decode() (any, error) {
+	if d.depth++; d.depth > maxDepth {
+		return nil, fmt.Errorf("msgpack: exceeded max depth %d", maxDepth)
+	}
+	defer func() { d.depth-- }()
+	b, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+	switch {
+	case c <= 0x7f:
+		return intNumber(int64(c)), nil
+	case c >= 0xe0:
+		return intNumber(int64(int8(c))), nil
+	case c&0xe0 == 0xa0:
+		return d.decodeString(int(c & 0x1f))
+	case c&0xf0 == 0x90:
+		return d.decodeArray(int(c & 0x0f))
+	case c&0xf0 == 0x80:
+		return d.decodeMap(int(c & 0x0f))
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.readUint(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return uintNumber(u), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		u, err := d.readUint(size)
+		if err != nil {
+			return nil, err
+		}
+		shift := 64 - 8*size
+		return intNumber(int64(u<<shift) >> shift), nil
+	case 0xca:
+		u, err := d.readUint(4)
+		if err != nil {
+			return nil, err
+		}
+		return floatNumber(float64(math.Float32frombits(uint32(u))))
+	case 0xcb:
+		u, err := d.readUint(8)
+		if err != nil {
+			return nil, err
+		}
+		return floatNumber(math.Float64frombits(u))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readUint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(int(n))
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.readUint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		raw, err := d.read(int(n))
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), raw...), nil
+	case 0xdc, 0xdd:
+		n, err := d.readUint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(int(n))
+	case 0xde, 0xdf:
+		n, err := d.readUint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(int(n))
+	}
+	return nil, fmt.Errorf("msgpack: unsupported format 0x%02x", c)
+}
+
+func (d *msgpackDecoder) decodeString(n int) (string, error) {
+	b, err := d.read(n)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (d *msgpackDecoder) decodeArray(n int) ([]any, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errMsgpackShort
+	}
+	arr := make([]any, n)
+	for i := range arr {
+		v, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		arr[i] = v
+	}
+	return arr, nil
+}
+
+func (d *msgpackDecoder) decodeMap(n int) (map[string]any, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errMsgpackShort
+	}
+	m := make(map[string]any, n)
+	for i := 0; i < n; i++ {
+		k, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("msgpack: map key of type %T is not a string", k)
+		}
+		v, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
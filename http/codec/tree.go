@@ -0,0 +1,89 @@
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// maxDepth bounds the nesting of decoded trees, as encoding/json does, so
+// that hostile input can not exhaust the stack of the decoders.
+const maxDepth = 10000
+
+// toTree converts v to the JSON data model by way of encoding/json, so the
+// binary codecs honour json tags and json.Marshaler exactly like JSON does.
+// Numbers in the tree are json.Number.
+func toTree(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var tree any
+	err = jsonCodec{}.Unmarshal(data, &tree)
+	return tree, err
+}
+
+// fromTree stores a decoded tree into v with encoding/json semantics.
+// Byte strings of the binary formats arrive as base64 strings, the way
+// encoding/json represents []byte.
+func fromTree(tree any, v any) error {
+	if p, ok := v.(*any); ok {
+		*p = tree
+		return nil
+	}
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	return jsonCodec{}.Unmarshal(data, v)
+}
+
+// number is a json.Number split into the representation a binary format
+// should use for it.
+type number struct {
+	isInt    bool
+	negative bool
+	// magnitude of an integer, valid when isInt
+	u uint64
+	// big integers that do not fit in 64 bits
+	big *big.Int
+	f   float64
+}
+
+func parseNumber(n json.Number) (number, error) {
+	s := string(n)
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if i < 0 {
+			return number{isInt: true, negative: true, u: uint64(-(i + 1)) + 1}, nil
+		}
+		return number{isInt: true, u: uint64(i)}, nil
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return number{isInt: true, u: u}, nil
+	}
+	if b, ok := new(big.Int).SetString(s, 10); ok {
+		return number{isInt: true, negative: b.Sign() < 0, big: b}, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return number{}, fmt.Errorf("invalid number %q", s)
+	}
+	return number{f: f}, nil
+}
+
+func intNumber(i int64) json.Number {
+	return json.Number(strconv.FormatInt(i, 10))
+}
+
+func uintNumber(u uint64) json.Number {
+	return json.Number(strconv.FormatUint(u, 10))
+}
+
+func floatNumber(f float64) (json.Number, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("unsupported float value %v", f)
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64)), nil
+}
@@ -1,7 +1,72 @@
 package constant
 
 const (
-	RouteHealth = "/health"
-	RouteMeta   = "/meta"
-	RouteMethod = "/component/%s/methods/%s"
+	RouteHealth    = "/health"
+	RouteMeta      = "/meta"
+	RouteMetrics   = "/metrics"
+	RouteLimits    = "/limits"
+	RouteBulkheads = "/bulkheads"
+	RouteOpenAPI   = "/openapi.json"
+	// RoutePlayground is served only when the playground is enabled.
+	RoutePlayground = "/playground"
+	RouteMethod     = "/component/%s/methods/%s"
+	// RouteVersionedMethod is the method route of a versioned service.
+	RouteVersionedMethod = "/component/%s/versions/%s/methods/%s"
+	// RouteScope prefixes the method routes of services with a namespace or
+	// a group, ScopeDefault stands for the empty one.
+	RouteScope   = "/namespaces/%s/groups/%s"
+	ScopeDefault = "-"
+	RouteCall    = "/calls/%s"
+)
+
+// Routes of the admin group, served under RouteAdmin.
+const (
+	RouteAdmin            = "/admin"
+	RouteAdminRoutes      = "/routes"
+	RouteAdminCalls       = "/calls"
+	RouteAdminMetrics     = "/metrics"
+	RouteAdminMethods     = "/methods"
+	RouteAdminMaintenance = "/maintenance"
+)
+
+// HeaderCodecs lists the codecs a server accepts on the RouteMeta response,
+// separated by commas. Servers without it only speak JSON.
+const HeaderCodecs = "X-Remote-Codecs"
+
+// HeaderCallId identifies an invocation, a DELETE on RouteCall with the same
+// id cancels its context on the server.
+const HeaderCallId = "X-Remote-Call-Id"
+
+// HeaderIdempotencyKey identifies a logical invocation across its retries,
+// servers storing responses replay the one of its first completed attempt.
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// HeaderIdempotency is set to true on the RouteMeta response of servers
+// storing responses, whose clients may retry calls that were lost under
+// their idempotency keys.
+const HeaderIdempotency = "X-Remote-Idempotency"
+
+// HeaderReplayed marks a response replayed from a previous attempt.
+const HeaderReplayed = "X-Remote-Replayed"
+
+// HeaderErrorKind marks a non-success invocation response with the class of
+// the failure, the body holds the matching dto error.
+const HeaderErrorKind = "X-Remote-Error"
+
+const (
+	ErrorKindValidate = "validate"
+	ErrorKindConvert  = "convert"
+	ErrorKindPanic    = "panic"
+	ErrorKindThrottle = "throttle"
+	ErrorKindOverload = "overload"
+	// ErrorKindUnavailable answers calls to disabled methods or to a server
+	// in maintenance.
+	ErrorKindUnavailable = "unavailable"
+)
+
+// Routes of the embedded service registry.
+const (
+	RouteLeases    = "/registry/leases"
+	RouteLease     = "/registry/leases/%s"
+	RouteInstances = "/registry/services/%s/instances"
 )
@@ -0,0 +1,131 @@
+package discovery
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Instance is a server that may export a service.
+type Instance struct {
+	// Addr is the base URL of the server, such as http://10.0.0.1:8080.
+	Addr        string `json:"addr"`
+	RoutePrefix string `json:"route_prefix,omitempty"`
+	// Namespace and Group restrict the services used from the instance to
+	// the ones placed in them, empty ones do not restrict.
+	Namespace string `json:"namespace,omitempty"`
+	Group     string `json:"group,omitempty"`
+	Zone      string `json:"zone,omitempty"`
+	// Weight is the relative share of calls the instance should take.
+	Weight int `json:"weight,omitempty"`
+	// Version is the version of the server build, service versions are
+	// read from the server itself.
+	Version  string            `json:"version,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// URL is the address the routes of the instance are relative to.
+func (i *Instance) URL() string {
+	return i.Addr + i.RoutePrefix
+}
+
+// Discovery finds the instances of services.
+type Discovery interface {
+	// Watch holds the current instances of the service in the returned
+	// channel when it returns, and sends the full set again whenever it
+	// changes. The channel is closed once ctx is done.
+	Watch(ctx context.Context, serviceId string) (<-chan []*Instance, error)
+}
+
+// LookupFunc returns the current instances of a service.
+type LookupFunc func(ctx context.Context, serviceId string) ([]*Instance, error)
+
+// Static discovers the same instances for every service.
+func Static(instances ...*Instance) Discovery {
+	return static(instances)
+}
+
+type static []*Instance
+
+func (s static) Watch(ctx context.Context, _ string) (<-chan []*Instance, error) {
+	ch := make(chan []*Instance, 1)
+	ch <- s
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch, nil
+}
+
+// Func discovers instances with lookup, which is called again every refresh
+// interval. A failed refresh keeps the last instances.
+func Func(lookup LookupFunc, refresh time.Duration) Discovery {
+	if refresh <= 0 {
+		refresh = DefaultRefresh
+	}
+	return &poller{lookup: lookup, refresh: refresh}
+}
+
+// DefaultRefresh is the refresh interval of polling discoveries.
+const DefaultRefresh = 30 * time.Second
+
+type poller struct {
+	lookup  LookupFunc
+	refresh time.Duration
+}
+
+func (p *poller) Watch(ctx context.Context, serviceId string) (<-chan []*Instance, error) {
+	last, err := p.lookup(ctx, serviceId)
+	if err != nil {
+		return nil, err
+	}
+	last = sorted(last)
+	ch := make(chan []*Instance, 1)
+	ch <- last
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(p.refresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			instances, err := p.lookup(ctx, serviceId)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("[remote-ioc] discover %s failed, keeping %d instances: %v", serviceId, len(last), err)
+				}
+				continue
+			}
+			instances = sorted(instances)
+			if reflect.DeepEqual(instances, last) {
+				continue
+			}
+			last = instances
+			Publish(ch, instances)
+		}
+	}()
+	return ch, nil
+}
+
+// Publish replaces the set waiting in ch, a slow watcher only misses the
+// intermediate sets. ch must have a buffer of one and a single sender.
+func Publish(ch chan []*Instance, instances []*Instance) {
+	select {
+	case <-ch:
+	default:
+	}
+	ch <- instances
+}
+
+func sorted(instances []*Instance) []*Instance {
+	out := append([]*Instance(nil), instances...)
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].URL() < out[j].URL()
+	})
+	return out
+}
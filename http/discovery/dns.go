@@ -0,0 +1,84 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SRVResolver looks up SRV records, *net.Resolver implements it.
+type SRVResolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+type DNSConfig struct {
+	// Domain is searched for the records, such as "svc.example.com".
+	Domain string
+	// Proto is the SRV protocol label, "tcp" when empty.
+	Proto string
+	// Service maps a service id to its SRV service label, the lower-cased
+	// id when nil.
+	Service func(serviceId string) string
+	// Scheme of the instance addresses, "http" when empty.
+	Scheme string
+	// Zone reads the zone of a target host, such as the first label of
+	// "a.eu-west.svc.example.com.", no zones when nil.
+	Zone     func(target string) string
+	Resolver SRVResolver
+	// Refresh is the lookup interval, DefaultRefresh when zero.
+	Refresh time.Duration
+}
+
+// DNS discovers the instances of a service from the SRV records
+// _<service>._<proto>.<domain>. Only the targets of the lowest priority are
+// used, the others are standby. Record weights become instance weights.
+func DNS(c DNSConfig) Discovery {
+	if c.Proto == "" {
+		c.Proto = "tcp"
+	}
+	if c.Service == nil {
+		c.Service = strings.ToLower
+	}
+	if c.Scheme == "" {
+		c.Scheme = "http"
+	}
+	if c.Resolver == nil {
+		c.Resolver = net.DefaultResolver
+	}
+	return Func(c.lookup, c.Refresh)
+}
+
+func (c DNSConfig) lookup(ctx context.Context, serviceId string) ([]*Instance, error) {
+	_, records, err := c.Resolver.LookupSRV(ctx, c.Service(serviceId), c.Proto, c.Domain)
+	if err != nil {
+		return nil, fmt.Errorf("lookup SRV of %s: %v", serviceId, err)
+	}
+	var (
+		instances []*Instance
+		priority  uint16
+	)
+	for i, record := range records {
+		if i == 0 || record.Priority < priority {
+			priority = record.Priority
+		}
+	}
+	for _, record := range records {
+		if record.Priority != priority {
+			continue
+		}
+		target := strings.TrimSuffix(record.Target, ".")
+		instance := &Instance{
+			Addr:     c.Scheme + "://" + net.JoinHostPort(target, strconv.Itoa(int(record.Port))),
+			Weight:   int(record.Weight),
+			Metadata: map[string]string{"priority": strconv.Itoa(int(record.Priority))},
+		}
+		if c.Zone != nil {
+			instance.Zone = c.Zone(record.Target)
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
@@ -0,0 +1,137 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v3"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+// File discovers the instances listed for each service id in a YAML or JSON
+// file, keyed like the json tags of Instance:
+//
+//	Orders:
+//	  - addr: http://10.0.0.1:8080
+//	    zone: eu
+//	    weight: 2
+//
+// The file is watched and every valid edit replaces the instances at once.
+// An edit that does not parse is logged and the last instances are kept.
+func File(path string) Discovery {
+	return &file{path: path}
+}
+
+// fileSettle is how long the events of one edit are gathered before the file
+// is read again, editors often truncate and write in separate steps.
+const fileSettle = 50 * time.Millisecond
+
+type file struct {
+	path string
+}
+
+func (f *file) Watch(ctx context.Context, serviceId string) (<-chan []*Instance, error) {
+	// the directory is watched, editors and config maps replace files
+	// through renames that a watch on the file itself loses
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err = watcher.Add(filepath.Dir(f.path)); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("watch %s: %v", f.path, err)
+	}
+	services, err := ReadFile(f.path)
+	if err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	last := sorted(services[serviceId])
+	ch := make(chan []*Instance, 1)
+	ch <- last
+	go func() {
+		defer close(ch)
+		defer watcher.Close()
+		var settle <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) == filepath.Clean(f.path) {
+					settle = time.After(fileSettle)
+				}
+				continue
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("[remote-ioc] watch %s: %v", f.path, err)
+				continue
+			case <-settle:
+				settle = nil
+			}
+			services, err := ReadFile(f.path)
+			if err != nil {
+				log.Printf("[remote-ioc] keeping %d instances of %s: %v", len(last), serviceId, err)
+				continue
+			}
+			instances := sorted(services[serviceId])
+			if reflect.DeepEqual(instances, last) {
+				continue
+			}
+			last = instances
+			Publish(ch, instances)
+		}
+	}()
+	return ch, nil
+}
+
+// ReadFile reads the instances of each service id from a YAML or JSON file.
+// Empty files, unknown keys and instances without a valid address are
+// rejected, an empty set of services is written as {}.
+func ReadFile(path string) (map[string][]*Instance, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, fmt.Errorf("parse %s: the file is empty", path)
+	}
+	// JSON is YAML, both are read as YAML and checked against the json tags
+	var raw map[string]any
+	if err = yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse %s: %v", path, err)
+	}
+	converted, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %v", path, err)
+	}
+	var services map[string][]*Instance
+	decoder := json.NewDecoder(bytes.NewReader(converted))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&services); err != nil {
+		return nil, fmt.Errorf("parse %s: %v", path, err)
+	}
+	for serviceId, instances := range services {
+		for i, instance := range instances {
+			if instance == nil {
+				return nil, fmt.Errorf("parse %s: %s[%d] is empty", path, serviceId, i)
+			}
+			if u, err := url.Parse(instance.Addr); err != nil || u.Scheme == "" || u.Host == "" {
+				return nil, fmt.Errorf("parse %s: %s[%d] has an invalid addr %q", path, serviceId, i, instance.Addr)
+			}
+		}
+	}
+	return services, nil
+}
@@ -7,7 +7,11 @@ type ValidateError struct {
 	RequiredParamKind string `json:"required_param_kind"`
 	RequestParamKind  string `json:"request_param_kind"`
 	ParamOrder        int    `json:"param_order"`
-	Value             any    `json:"value"`
+	// Field is the path of the failing field and Rule the rule it broke,
+	// both are empty when the parameter kind itself is wrong.
+	Field string `json:"field,omitempty"`
+	Rule  string `json:"rule,omitempty"`
+	Value any    `json:"value"`
 }
 
 func (e *ValidateError) Error() string {
@@ -24,3 +28,58 @@ func (e *ConvertError) Error() string {
 	marshal, _ := json.Marshal(e)
 	return string(marshal)
 }
+
+// ErrorEnvelope is the wire form of an error value and its cause chain.
+type ErrorEnvelope struct {
+	Code    string         `json:"code,omitempty"`
+	Message string         `json:"message"`
+	Type    string         `json:"type,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+	// Value holds the fields of an error whose type is registered.
+	Value any            `json:"value,omitempty"`
+	Cause *ErrorEnvelope `json:"cause,omitempty"`
+	// Causes are the errors of an error wrapping several, such as with
+	// errors.Join, Cause is unset then.
+	Causes []*ErrorEnvelope `json:"causes,omitempty"`
+}
+
+// RemoteError stands in for a remote error whose type is not registered
+// on the receiving side. It unwraps to the rebuilt cause, so registered
+// errors further down the chain still match errors.Is and errors.As.
+type RemoteError struct {
+	Code    string         `json:"code,omitempty"`
+	Message string         `json:"message"`
+	Type    string         `json:"type,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+	cause   error
+}
+
+func NewRemoteError(env *ErrorEnvelope, cause error) *RemoteError {
+	return &RemoteError{
+		Code:    env.Code,
+		Message: env.Message,
+		Type:    env.Type,
+		Details: env.Details,
+		cause:   cause,
+	}
+}
+
+func (e *RemoteError) Error() string {
+	return e.Message
+}
+
+func (e *RemoteError) Unwrap() error {
+	return e.cause
+}
+
+// PanicError reports a panic raised by a remote method.
+type PanicError struct {
+	ServiceId string `json:"service_id"`
+	Method    string `json:"method"`
+	Message   string `json:"message"`
+	Stack     string `json:"stack,omitempty"`
+}
+
+func (e *PanicError) Error() string {
+	return "remote method " + e.ServiceId + "." + e.Method + " panic: " + e.Message
+}
@@ -11,9 +11,18 @@ type Payload struct {
 type Param struct {
 	Order int    `json:"order"`
 	Kind  string `json:"kind"`
+	// Type is the registered name of the concrete type of an interface value.
+	Type  string `json:"type,omitempty"`
 	Value any    `json:"value"`
 }
 
+// TypedValue carries an interface value nested in another value together
+// with the registered name of its concrete type.
+type TypedValue struct {
+	Type  string `json:"$type"`
+	Value any    `json:"$value"`
+}
+
 func (p *Param) Validate(in reflect.Type) error {
 	if in.Kind().String() == p.Kind {
 		return nil
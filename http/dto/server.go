@@ -1,6 +1,123 @@
 package dto
 
+import (
+	"fmt"
+	"github.com/go-kid/remote-ioc/http/constant"
+	"time"
+)
+
+// ServiceKey identifies an exported service. Services of the same id in
+// other namespaces or groups are unrelated.
+type ServiceKey struct {
+	Namespace string `json:"namespace,omitempty"`
+	Group     string `json:"group,omitempty"`
+	ServiceId string `json:"service_id"`
+	// Version is the semantic version of the service, empty if unversioned.
+	Version string `json:"version,omitempty"`
+}
+
+// Route returns the route of a method of the service.
+func (k ServiceKey) Route(method string) string {
+	var route string
+	if k.Version == "" {
+		route = fmt.Sprintf(constant.RouteMethod, k.ServiceId, method)
+	} else {
+		route = fmt.Sprintf(constant.RouteVersionedMethod, k.ServiceId, k.Version, method)
+	}
+	if k.Namespace != "" || k.Group != "" {
+		route = fmt.Sprintf(constant.RouteScope, scope(k.Namespace), scope(k.Group)) + route
+	}
+	return route
+}
+
+func scope(s string) string {
+	if s == "" {
+		return constant.ScopeDefault
+	}
+	return s
+}
+
+func (k ServiceKey) String() string {
+	s := k.ServiceId
+	if k.Namespace != "" || k.Group != "" {
+		s = scope(k.Namespace) + "/" + scope(k.Group) + "/" + s
+	}
+	if k.Version != "" {
+		s += "@" + k.Version
+	}
+	return s
+}
+
 type ServerInfo struct {
-	ServiceId string   `json:"service_id"`
-	Methods   []string `json:"methods"`
+	ServiceKey
+	Methods []string `json:"methods"`
+}
+
+type MethodMetrics struct {
+	ServiceKey
+	Method   string `json:"method"`
+	Calls    int64  `json:"calls"`
+	Failures int64  `json:"failures"`
+	Panics   int64  `json:"panics"`
+	// Throttled counts the calls rejected by rate limits.
+	Throttled int64 `json:"throttled,omitempty"`
+	// Replayed counts the calls answered with a stored response.
+	Replayed int64 `json:"replayed,omitempty"`
+}
+
+// BulkheadState is the saturation of a bulkhead, Method is empty for the
+// bulkhead a service shares among its methods. Peak is the highest number of
+// concurrent executions seen.
+type BulkheadState struct {
+	ServiceKey
+	Method        string `json:"method,omitempty"`
+	MaxConcurrent int    `json:"max_concurrent"`
+	MaxQueue      int    `json:"max_queue"`
+	Active        int    `json:"active"`
+	Queued        int64  `json:"queued"`
+	Peak          int64  `json:"peak"`
+	Admitted      int64  `json:"admitted"`
+	Rejected      int64  `json:"rejected"`
+	TimedOut      int64  `json:"timed_out"`
+}
+
+// LimitState is the state of a rate limit bucket. Scope is global, service,
+// method or caller, Key names the service key, the service key and method
+// or the caller.
+type LimitState struct {
+	Scope    string  `json:"scope"`
+	Key      string  `json:"key,omitempty"`
+	Rate     float64 `json:"rate"`
+	Burst    int     `json:"burst"`
+	Tokens   float64 `json:"tokens"`
+	Rejected int64   `json:"rejected"`
+}
+
+// CallState is a running invocation, Id is the call id its client sent.
+type CallState struct {
+	Id string `json:"id,omitempty"`
+	ServiceKey
+	Method     string    `json:"method"`
+	Caller     string    `json:"caller"`
+	Started    time.Time `json:"started"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// Route is a route the server answers.
+type Route struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// MethodState tells whether an exported method accepts calls.
+type MethodState struct {
+	ServiceKey
+	Method  string `json:"method"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Maintenance turns every invocation away with Message while Enabled.
+type Maintenance struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message,omitempty"`
 }
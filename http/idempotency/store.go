@@ -0,0 +1,246 @@
+// Package idempotency stores the completed responses of invocations by their
+// idempotency keys, so that servers replay them to retries instead of
+// running the methods again.
+package idempotency
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Response is a completed invocation response.
+type Response struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	// ErrorKind is the X-Remote-Error of the response, empty on success.
+	ErrorKind string `json:"error_kind,omitempty"`
+	Body      []byte `json:"body"`
+	// Digest is the hash of the request payload, calls sent again with the
+	// key must send the same one.
+	Digest string `json:"digest,omitempty"`
+}
+
+// Store keeps responses until their TTL expires. Keys are scoped by the
+// server to the caller, the service and the method they were sent to.
+type Store interface {
+	// Get returns the response stored under key, false when there is none
+	// or it expired.
+	Get(ctx context.Context, key string) (*Response, bool, error)
+	Put(ctx context.Context, key string, r *Response, ttl time.Duration) error
+}
+
+// sweepInterval is how often expired responses are dropped.
+const sweepInterval = time.Minute
+
+type entry struct {
+	Response  *Response `json:"response"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// DefaultMaxEntries bounds the responses of Memory.
+const DefaultMaxEntries = 10000
+
+// MemoryStore keeps the responses in the process, they are lost on restart.
+// Beyond its bound the oldest responses are dropped.
+type MemoryStore struct {
+	maxEntries int
+
+	mu sync.Mutex
+	// entries hold the elements of order, the oldest put first
+	entries   map[string]*list.Element
+	order     *list.List
+	lastSweep time.Time
+}
+
+type memoryEntry struct {
+	key string
+	entry
+}
+
+// Memory keeps up to DefaultMaxEntries responses.
+func Memory() *MemoryStore {
+	return BoundedMemory(DefaultMaxEntries)
+}
+
+// BoundedMemory keeps up to maxEntries responses.
+func BoundedMemory(maxEntries int) *MemoryStore {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		lastSweep:  time.Now(),
+	}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (*Response, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok || !time.Now().Before(e.Value.(*memoryEntry).ExpiresAt) {
+		return nil, false, nil
+	}
+	return e.Value.(*memoryEntry).Response, true, nil
+}
+
+func (s *MemoryStore) Put(_ context.Context, key string, r *Response, ttl time.Duration) error {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastSweep) > sweepInterval {
+		for k, e := range s.entries {
+			if !now.Before(e.Value.(*memoryEntry).ExpiresAt) {
+				s.order.Remove(e)
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	if e, ok := s.entries[key]; ok {
+		s.order.Remove(e)
+		delete(s.entries, key)
+	}
+	if s.order.Len() >= s.maxEntries {
+		oldest := s.order.Front()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*memoryEntry).key)
+	}
+	s.entries[key] = s.order.PushBack(&memoryEntry{key: key, entry: entry{Response: r, ExpiresAt: now.Add(ttl)}})
+	return nil
+}
+
+// FileStore keeps each response in a file of its directory, so that they
+// survive restarts. Responses are written to .tmp files first, the ones left
+// behind by a crash are removed once they are older than a sweep interval.
+type FileStore struct {
+	dir string
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// File stores the responses in dir, creating it when missing, and sweeps
+// what a former process left in it.
+func File(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("idempotency store: %v", err)
+	}
+	s := &FileStore{dir: dir}
+	s.sweep()
+	return s, nil
+}
+
+// path hashes the key, which is not a safe file name.
+func (s *FileStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get misses the responses in corrupt files, such as ones truncated by a
+// crash, and removes them so that the call runs again.
+func (s *FileStore) Get(_ context.Context, key string) (*Response, bool, error) {
+	path := s.path(key)
+	e, err := readEntry(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if errors.Is(err, errCorrupt) {
+		log.Printf("[remote-ioc] %v, removing it", err)
+		_ = os.Remove(path)
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if !time.Now().Before(e.ExpiresAt) {
+		return nil, false, nil
+	}
+	return e.Response, true, nil
+}
+
+func (s *FileStore) Put(_ context.Context, key string, r *Response, ttl time.Duration) error {
+	s.sweep()
+	data, err := json.Marshal(&entry{Response: r, ExpiresAt: time.Now().Add(ttl)})
+	if err != nil {
+		return err
+	}
+	path := s.path(key)
+	tmp, err := os.CreateTemp(s.dir, filepath.Base(path)+".*"+tmpSuffix)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+const tmpSuffix = ".tmp"
+
+// sweep removes the expired and corrupt responses, and the temporary files
+// of the writes that did not complete, at most once per sweepInterval.
+func (s *FileStore) sweep() {
+	now := time.Now()
+	s.mu.Lock()
+	if now.Sub(s.lastSweep) <= sweepInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSweep = now
+	s.mu.Unlock()
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		path := filepath.Join(s.dir, f.Name())
+		if strings.HasSuffix(f.Name(), tmpSuffix) {
+			// younger ones may still be written
+			if info, err := f.Info(); err == nil && now.Sub(info.ModTime()) > sweepInterval {
+				_ = os.Remove(path)
+			}
+			continue
+		}
+		if !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		if e, err := readEntry(path); errors.Is(err, errCorrupt) || (err == nil && !now.Before(e.ExpiresAt)) {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+var errCorrupt = errors.New("corrupt entry")
+
+func readEntry(path string) (*entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var e entry
+	if err = json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("idempotency store %s: %w: %v", path, errCorrupt, err)
+	}
+	return &e, nil
+}
@@ -0,0 +1,158 @@
+package naming
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/go-kid/remote-ioc/http/discovery"
+	"github.com/go-resty/resty/v2"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type ClientConfig struct {
+	// Addr is the base URL of the registry, its route prefix included.
+	Addr    string
+	Headers map[string]string
+	// Wait is the long polling time of watches, capped by the MaxWait of the
+	// registry. DefaultMaxWait when zero.
+	Wait time.Duration
+	// Retry is the pause of a watch after a failed poll, one second when
+	// zero.
+	Retry time.Duration
+	// Timeout bounds each request, on top of the wait of a long poll.
+	// DefaultClientTimeout when zero.
+	Timeout time.Duration
+}
+
+const DefaultClientTimeout = 10 * time.Second
+
+// Client reaches a registry served over HTTP. It is a discovery of the
+// registered instances.
+type Client struct {
+	c    ClientConfig
+	http *resty.Client
+}
+
+func NewClient(c ClientConfig) *Client {
+	if c.Wait <= 0 {
+		c.Wait = DefaultMaxWait
+	}
+	if c.Retry <= 0 {
+		c.Retry = time.Second
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultClientTimeout
+	}
+	return &Client{
+		c:    c,
+		http: resty.New().SetHeaders(c.Headers),
+	}
+}
+
+// request bounds a request to the registry by the Timeout, past wait.
+func (c *Client) request(ctx context.Context, wait time.Duration) (*resty.Request, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(ctx, wait+c.c.Timeout)
+	return c.http.R().SetContext(ctx), cancel
+}
+
+func (c *Client) Register(ctx context.Context, reg *Registration) (*Lease, error) {
+	var lease = &Lease{}
+	r, cancel := c.request(ctx, 0)
+	defer cancel()
+	response, err := r.
+		SetBody(reg).
+		SetResult(lease).
+		Post(c.c.Addr + constant.RouteLeases)
+	if err = responseError(response, err); err != nil {
+		return nil, fmt.Errorf("register %s: %v", reg.Instance.URL(), err)
+	}
+	return lease, nil
+}
+
+func (c *Client) KeepAlive(ctx context.Context, id string) (*Lease, error) {
+	var lease = &Lease{}
+	r, cancel := c.request(ctx, 0)
+	defer cancel()
+	response, err := r.
+		SetResult(lease).
+		Put(c.c.Addr + fmt.Sprintf(constant.RouteLease, id))
+	if err = responseError(response, err); err != nil {
+		return nil, err
+	}
+	return lease, nil
+}
+
+func (c *Client) Deregister(ctx context.Context, id string) error {
+	r, cancel := c.request(ctx, 0)
+	defer cancel()
+	response, err := r.
+		Delete(c.c.Addr + fmt.Sprintf(constant.RouteLease, id))
+	return responseError(response, err)
+}
+
+// Instances polls the instances of a service past index, waiting up to wait.
+func (c *Client) Instances(ctx context.Context, serviceId string, index uint64, wait time.Duration) (*Instances, error) {
+	var instances = &Instances{}
+	r, cancel := c.request(ctx, wait)
+	defer cancel()
+	response, err := r.
+		SetQueryParam("index", strconv.FormatUint(index, 10)).
+		SetQueryParam("wait", wait.String()).
+		SetResult(instances).
+		Get(c.c.Addr + fmt.Sprintf(constant.RouteInstances, url.PathEscape(serviceId)))
+	if err = responseError(response, err); err != nil {
+		return nil, fmt.Errorf("instances of %s: %v", serviceId, err)
+	}
+	return instances, nil
+}
+
+// Watch long polls the registry. Failed polls are retried and keep the last
+// instances.
+func (c *Client) Watch(ctx context.Context, serviceId string) (<-chan []*discovery.Instance, error) {
+	state, err := c.Instances(ctx, serviceId, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan []*discovery.Instance, 1)
+	ch <- state.Instances
+	go func() {
+		defer close(ch)
+		for ctx.Err() == nil {
+			next, err := c.Instances(ctx, serviceId, state.Index, c.c.Wait)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Printf("[remote-ioc] watch %s on %s failed, retrying: %v", serviceId, c.c.Addr, err)
+				select {
+				case <-ctx.Done():
+				case <-time.After(c.c.Retry):
+				}
+				continue
+			}
+			if next.Index != state.Index {
+				discovery.Publish(ch, next.Instances)
+			}
+			state = next
+		}
+	}()
+	return ch, nil
+}
+
+func responseError(response *resty.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	if response.StatusCode() == http.StatusNotFound &&
+		(response.Request.Method == http.MethodPut || response.Request.Method == http.MethodDelete) {
+		return ErrLeaseNotFound
+	}
+	if response.IsError() {
+		return fmt.Errorf("registry: %s %s", response.Status(), response.String())
+	}
+	return nil
+}
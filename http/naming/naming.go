@@ -0,0 +1,71 @@
+package naming
+
+import (
+	"context"
+	"errors"
+	"github.com/go-kid/remote-ioc/http/discovery"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/samber/lo"
+	"time"
+)
+
+// Registration announces an instance exporting some services.
+type Registration struct {
+	Instance *discovery.Instance `json:"instance"`
+	// Services are the full keys of the exported services, with their
+	// namespaces, groups and versions.
+	Services []dto.ServiceKey `json:"services"`
+	// TTL is the lease time to live in seconds, the default TTL of the
+	// registry when zero.
+	TTL int `json:"ttl,omitempty"`
+}
+
+// Lease keeps a registration until it expires, keep alives extend it by its
+// TTL.
+type Lease struct {
+	Id        string              `json:"id"`
+	Instance  *discovery.Instance `json:"instance"`
+	Services  []dto.ServiceKey    `json:"services"`
+	TTL       int                 `json:"ttl"`
+	ExpiresAt time.Time           `json:"expires_at"`
+}
+
+// ServiceIds are the ids of the services of the lease, each one once.
+func (l *Lease) ServiceIds() []string {
+	return serviceIds(l.Services)
+}
+
+func serviceIds(keys []dto.ServiceKey) []string {
+	var ids []string
+	for _, key := range keys {
+		if !lo.Contains(ids, key.ServiceId) {
+			ids = append(ids, key.ServiceId)
+		}
+	}
+	return ids
+}
+
+// Instances is the state of a service at a registry index, watches wait for
+// an index past it.
+type Instances struct {
+	Index     uint64                `json:"index"`
+	Instances []*discovery.Instance `json:"instances"`
+}
+
+// ErrLeaseNotFound is returned for an expired or deregistered lease, the
+// instance has to register again.
+var ErrLeaseNotFound = errors.New("lease not found")
+
+// Registrar keeps leases, Registry does in process and Client over HTTP.
+type Registrar interface {
+	Register(ctx context.Context, reg *Registration) (*Lease, error)
+	KeepAlive(ctx context.Context, id string) (*Lease, error)
+	Deregister(ctx context.Context, id string) error
+}
+
+var (
+	_ Registrar           = (*Registry)(nil)
+	_ Registrar           = (*Client)(nil)
+	_ discovery.Discovery = (*Registry)(nil)
+	_ discovery.Discovery = (*Client)(nil)
+)
@@ -0,0 +1,392 @@
+package naming
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/go-kid/remote-ioc/http/discovery"
+	"github.com/samber/lo"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Config struct {
+	Addr        string
+	RoutePrefix string
+	// TTL of the leases registered without one, DefaultTTL when zero.
+	TTL time.Duration
+	// MaxTTL bounds the TTL instances register with, so that the leases of
+	// crashed ones expire. DefaultMaxTTL when zero.
+	MaxTTL time.Duration
+	// MaxWait bounds the long polling of watches, DefaultMaxWait when zero.
+	MaxWait time.Duration
+	// File persists the leases. They are restored with a fresh TTL when the
+	// registry starts again, so live instances keep their registration. The
+	// leases are kept in memory only when empty.
+	File string
+	// Sweep is the interval expired leases are dropped at, one second when
+	// zero.
+	Sweep time.Duration
+	// Authenticate guards the registry routes, such as with
+	// server.BasicAuth. Nil leaves the registry open.
+	Authenticate func(r *http.Request) error
+}
+
+const (
+	DefaultTTL     = 30 * time.Second
+	DefaultMaxTTL  = 5 * time.Minute
+	DefaultMaxWait = time.Minute
+)
+
+// Registry keeps the leases of the registered instances. It is a discovery
+// of the registered instances itself, Serve exposes it over HTTP.
+type Registry struct {
+	c Config
+
+	mu     sync.Mutex
+	leases map[string]*Lease
+	// index counts the changes, indexes holds the index of the last change
+	// of each service.
+	index   uint64
+	indexes map[string]uint64
+	// changed is closed and replaced on every change.
+	changed chan struct{}
+
+	// persistMu orders the writes of the file, persisted is the index of
+	// the last written snapshot.
+	persistMu sync.Mutex
+	persisted uint64
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// New creates a registry and restores the leases of Config.File.
+func New(c Config) (*Registry, error) {
+	if c.TTL <= 0 {
+		c.TTL = DefaultTTL
+	}
+	if c.MaxTTL <= 0 {
+		c.MaxTTL = DefaultMaxTTL
+	}
+	if c.MaxWait <= 0 {
+		c.MaxWait = DefaultMaxWait
+	}
+	if c.Sweep <= 0 {
+		c.Sweep = time.Second
+	}
+	r := &Registry{
+		c:       c,
+		leases:  make(map[string]*Lease),
+		indexes: make(map[string]uint64),
+		changed: make(chan struct{}),
+		stop:    make(chan struct{}),
+	}
+	if err := r.restore(); err != nil {
+		return nil, err
+	}
+	go r.sweep()
+	return r, nil
+}
+
+// Close stops expiring leases.
+func (r *Registry) Close() {
+	r.closeOnce.Do(func() {
+		close(r.stop)
+	})
+}
+
+func (r *Registry) Register(_ context.Context, reg *Registration) (*Lease, error) {
+	if reg == nil || reg.Instance == nil || reg.Instance.Addr == "" {
+		return nil, fmt.Errorf("register: instance address is required")
+	}
+	if len(reg.Services) == 0 {
+		return nil, fmt.Errorf("register %s: no service id", reg.Instance.URL())
+	}
+	ttl := r.ttl(reg.TTL)
+	lease := &Lease{
+		Id:        newLeaseId(),
+		Instance:  reg.Instance,
+		Services:  reg.Services,
+		TTL:       ttl,
+		ExpiresAt: time.Now().Add(time.Duration(ttl) * time.Second),
+	}
+	r.mu.Lock()
+	r.leases[lease.Id] = lease
+	r.touch(lease.ServiceIds())
+	leaseCopy := *lease
+	r.mu.Unlock()
+	r.persist()
+	return &leaseCopy, nil
+}
+
+// ttl is the TTL in seconds of a lease registered with ttl, bounded by the
+// MaxTTL.
+func (r *Registry) ttl(ttl int) int {
+	if ttl <= 0 {
+		ttl = int(r.c.TTL / time.Second)
+	}
+	if limit := int(r.c.MaxTTL / time.Second); ttl > limit {
+		ttl = limit
+	}
+	if ttl <= 0 {
+		ttl = 1
+	}
+	return ttl
+}
+
+func (r *Registry) KeepAlive(_ context.Context, id string) (*Lease, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lease, ok := r.leases[id]
+	if !ok || time.Now().After(lease.ExpiresAt) {
+		return nil, ErrLeaseNotFound
+	}
+	lease.ExpiresAt = time.Now().Add(time.Duration(lease.TTL) * time.Second)
+	leaseCopy := *lease
+	return &leaseCopy, nil
+}
+
+func (r *Registry) Deregister(_ context.Context, id string) error {
+	r.mu.Lock()
+	lease, ok := r.leases[id]
+	if !ok {
+		r.mu.Unlock()
+		return ErrLeaseNotFound
+	}
+	delete(r.leases, id)
+	r.touch(lease.ServiceIds())
+	r.mu.Unlock()
+	r.persist()
+	return nil
+}
+
+// Leases returns the live leases ordered by id.
+func (r *Registry) Leases() []*Lease {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var leases []*Lease
+	for _, lease := range r.leases {
+		leaseCopy := *lease
+		leases = append(leases, &leaseCopy)
+	}
+	sort.Slice(leases, func(i, j int) bool {
+		return leases[i].Id < leases[j].Id
+	})
+	return leases
+}
+
+// Instances returns the registered instances of a service once its index
+// passed index, or the current ones when wait ran out first. An index past
+// the one of the registry, as kept by a watcher across a registry restart,
+// returns at once.
+func (r *Registry) Instances(ctx context.Context, serviceId string, index uint64, wait time.Duration) (*Instances, error) {
+	if wait > r.c.MaxWait {
+		wait = r.c.MaxWait
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		r.mu.Lock()
+		current, changed := r.indexes[serviceId], r.changed
+		if current > index || index > r.index || wait <= 0 {
+			instances := r.instances(serviceId)
+			r.mu.Unlock()
+			return &Instances{Index: current, Instances: instances}, nil
+		}
+		r.mu.Unlock()
+		select {
+		case <-changed:
+		case <-timer.C:
+			wait = 0
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (r *Registry) Watch(ctx context.Context, serviceId string) (<-chan []*discovery.Instance, error) {
+	state, err := r.Instances(ctx, serviceId, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan []*discovery.Instance, 1)
+	ch <- state.Instances
+	go func() {
+		defer close(ch)
+		for {
+			next, err := r.Instances(ctx, serviceId, state.Index, r.c.MaxWait)
+			if err != nil {
+				return
+			}
+			if next.Index != state.Index {
+				discovery.Publish(ch, next.Instances)
+			}
+			state = next
+		}
+	}()
+	return ch, nil
+}
+
+// instances returns the instances of the live leases of a service, one per
+// URL. r.mu must be held.
+func (r *Registry) instances(serviceId string) []*discovery.Instance {
+	var (
+		now    = time.Now()
+		latest = make(map[string]*Lease)
+	)
+	for _, lease := range r.leases {
+		if now.After(lease.ExpiresAt) || !lo.Contains(lease.ServiceIds(), serviceId) {
+			continue
+		}
+		url := lease.Instance.URL()
+		if l, ok := latest[url]; !ok || lease.ExpiresAt.After(l.ExpiresAt) {
+			latest[url] = lease
+		}
+	}
+	instances := make([]*discovery.Instance, 0, len(latest))
+	for _, lease := range latest {
+		instances = append(instances, lease.Instance)
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].URL() < instances[j].URL()
+	})
+	return instances
+}
+
+// touch records a change of the services and wakes the watches. r.mu must
+// be held, the change is persisted once it is released.
+func (r *Registry) touch(serviceIds []string) {
+	r.index++
+	for _, serviceId := range serviceIds {
+		r.indexes[serviceId] = r.index
+	}
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+func (r *Registry) sweep() {
+	ticker := time.NewTicker(r.c.Sweep)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+		r.mu.Lock()
+		now := time.Now()
+		var expired []string
+		for id, lease := range r.leases {
+			if now.After(lease.ExpiresAt) {
+				delete(r.leases, id)
+				expired = append(expired, lease.ServiceIds()...)
+			}
+		}
+		if len(expired) != 0 {
+			r.touch(expired)
+		}
+		r.mu.Unlock()
+		if len(expired) != 0 {
+			r.persist()
+		}
+	}
+}
+
+type snapshot struct {
+	Index  uint64   `json:"index"`
+	Leases []*Lease `json:"leases"`
+}
+
+// persist writes a snapshot of the leases to the file, without holding r.mu
+// during the write. A snapshot already written by a later change is not
+// written again.
+func (r *Registry) persist() {
+	if r.c.File == "" {
+		return
+	}
+	r.persistMu.Lock()
+	defer r.persistMu.Unlock()
+	r.mu.Lock()
+	s := &snapshot{Index: r.index}
+	for _, lease := range r.leases {
+		leaseCopy := *lease
+		s.Leases = append(s.Leases, &leaseCopy)
+	}
+	r.mu.Unlock()
+	if s.Index == r.persisted {
+		return
+	}
+	if err := r.write(s); err != nil {
+		log.Printf("[remote-ioc] persist registry to %s failed: %v", r.c.File, err)
+		return
+	}
+	r.persisted = s.Index
+}
+
+// write writes s to the file through a rename, so a crash leaves the former
+// file.
+func (r *Registry) write(s *snapshot) error {
+	sort.Slice(s.Leases, func(i, j int) bool {
+		return s.Leases[i].Id < s.Leases[j].Id
+	})
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.c.File), filepath.Base(r.c.File)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.c.File)
+}
+
+func (r *Registry) restore() error {
+	if r.c.File == "" {
+		return nil
+	}
+	data, err := os.ReadFile(r.c.File)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("restore registry: %v", err)
+	}
+	var s snapshot
+	if err = json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("restore registry from %s: %v", r.c.File, err)
+	}
+	now := time.Now()
+	r.index = s.Index
+	r.persisted = s.Index
+	for _, lease := range s.Leases {
+		lease.TTL = r.ttl(lease.TTL)
+		lease.ExpiresAt = now.Add(time.Duration(lease.TTL) * time.Second)
+		r.leases[lease.Id] = lease
+		for _, serviceId := range lease.ServiceIds() {
+			r.indexes[serviceId] = r.index
+		}
+	}
+	return nil
+}
+
+func newLeaseId() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
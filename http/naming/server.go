@@ -0,0 +1,129 @@
+package naming
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/labstack/echo/v4"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Serve runs the registry as a component listening on its Config.Addr. It
+// runs before the remote servers of the same app, so they can register to
+// it.
+func Serve(r *Registry) app.SettingOption {
+	return func(s *app.App) {
+		s.Register(&registryServer{r: r})
+	}
+}
+
+type registryServer struct {
+	r *Registry
+}
+
+func (s *registryServer) Order() int {
+	return 900
+}
+
+func (s *registryServer) Run() error {
+	if s.r.c.Addr == "" {
+		return fmt.Errorf("remote-ioc registry: Addr is required")
+	}
+	listener, err := net.Listen("tcp", s.r.c.Addr)
+	if err != nil {
+		return fmt.Errorf("remote-ioc registry: %v", err)
+	}
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.Listener = listener
+	s.r.routes(e.Group(s.r.c.RoutePrefix))
+	go func() {
+		log.Printf("[remote-ioc] registry started on: %s", s.r.c.Addr)
+		e.Logger.Fatal(
+			e.Start(s.r.c.Addr),
+		)
+	}()
+	return nil
+}
+
+// Handler serves the registry routes, for mounting it on another server.
+func (r *Registry) Handler() http.Handler {
+	e := echo.New()
+	r.routes(e.Group(r.c.RoutePrefix))
+	return e
+}
+
+func (r *Registry) routes(g *echo.Group) {
+	if r.c.Authenticate != nil {
+		g.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				if err := r.c.Authenticate(c.Request()); err != nil {
+					return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+				}
+				return next(c)
+			}
+		})
+	}
+	g.POST(constant.RouteLeases, func(c echo.Context) error {
+		var reg = &Registration{}
+		if err := c.Bind(reg); err != nil {
+			return err
+		}
+		lease, err := r.Register(c.Request().Context(), reg)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return c.JSON(http.StatusCreated, lease)
+	})
+	g.GET(constant.RouteLeases, func(c echo.Context) error {
+		return c.JSON(http.StatusOK, r.Leases())
+	})
+	g.PUT(fmt.Sprintf(constant.RouteLease, ":id"), func(c echo.Context) error {
+		lease, err := r.KeepAlive(c.Request().Context(), c.Param("id"))
+		if err != nil {
+			return leaseError(err)
+		}
+		return c.JSON(http.StatusOK, lease)
+	})
+	g.DELETE(fmt.Sprintf(constant.RouteLease, ":id"), func(c echo.Context) error {
+		if err := r.Deregister(c.Request().Context(), c.Param("id")); err != nil {
+			return leaseError(err)
+		}
+		return c.NoContent(http.StatusNoContent)
+	})
+	g.GET(fmt.Sprintf(constant.RouteInstances, ":service"), func(c echo.Context) error {
+		var (
+			index uint64
+			wait  time.Duration
+			err   error
+		)
+		if v := c.QueryParam("index"); v != "" {
+			if index, err = strconv.ParseUint(v, 10, 64); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "invalid index: "+v)
+			}
+		}
+		if v := c.QueryParam("wait"); v != "" {
+			if wait, err = time.ParseDuration(v); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "invalid wait: "+v)
+			}
+		}
+		instances, err := r.Instances(c.Request().Context(), c.Param("service"), index, wait)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, instances)
+	})
+}
+
+func leaseError(err error) error {
+	if errors.Is(err, ErrLeaseNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return err
+}
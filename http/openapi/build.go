@@ -0,0 +1,236 @@
+package openapi
+
+import (
+	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/transmission"
+	"reflect"
+	"strings"
+)
+
+// Method is an exported method of a remote component.
+type Method struct {
+	Service dto.ServiceKey
+	Name    string
+	// Type is the method type with the receiver as first parameter, as in
+	// reflect.Method.
+	Type reflect.Type
+}
+
+const kindDescription = "reflect kind of the Go parameter type, such as int64, string, struct, " +
+	"slice, map or ptr. Interface parameters use the interface type instead, such as " +
+	"context.Context or error, and name the concrete registered type in type."
+
+// Build describes the method routes, their dto.Payload envelopes and error
+// responses for every content type the server accepts.
+func Build(info *Info, contentTypes []string, methods []Method) *Document {
+	g := newGenerator()
+	param := g.schema(reflect.TypeOf(dto.Param{}))
+	g.schemas[g.names[reflect.TypeOf(dto.Param{})]].Properties["kind"].Description = kindDescription
+	g.schema(reflect.TypeOf(dto.Payload{}))
+
+	doc := &Document{
+		OpenAPI:    Version,
+		Info:       info,
+		Paths:      make(map[string]*PathItem),
+		Components: &Components{Schemas: g.schemas},
+	}
+	for _, m := range methods {
+		var ins, outs []reflect.Type
+		for i := 1; i < m.Type.NumIn(); i++ {
+			ins = append(ins, m.Type.In(i))
+		}
+		for i := 0; i < m.Type.NumOut(); i++ {
+			outs = append(outs, m.Type.Out(i))
+		}
+		var (
+			service     = m.Service.String()
+			operationId = invalidNameChars.ReplaceAllString(service+"_"+m.Name, "_")
+		)
+		doc.Paths[m.Service.Route(m.Name)] = &PathItem{
+			Post: &Operation{
+				OperationId: operationId,
+				Summary:     service + "." + m.Name,
+				Description: "Go signature: " + signature(m.Name, ins, outs, m.Type.IsVariadic()),
+				Tags:        []string{service},
+				Parameters: []*Parameter{{
+					Name:        constant.HeaderCallId,
+					In:          "header",
+					Description: "identifies the call among the running ones of the caller, so that it can be cancelled",
+					Schema:      &Schema{Type: "string"},
+				}},
+				RequestBody: &RequestBody{
+					Required: true,
+					Content:  content(contentTypes, g.payloadSchema(ins, param)),
+				},
+				Responses: g.responses(contentTypes, g.payloadSchema(outs, param)),
+			},
+		}
+	}
+	return doc
+}
+
+// payloadSchema is dto.Payload narrowed to the params of types, each one
+// pinned to its order and kind.
+func (g *generator) payloadSchema(types []reflect.Type, param *Schema) *Schema {
+	items := &Schema{}
+	for i, t := range types {
+		items.OneOf = append(items.OneOf, g.paramSchema(i+1, t))
+	}
+	if len(types) == 0 {
+		items = param
+	}
+	return &Schema{
+		Type:     "object",
+		Required: []string{"params"},
+		Properties: map[string]*Schema{
+			"params": {Type: "array", Items: items, MaxItems: integer(len(types))},
+		},
+	}
+}
+
+func (g *generator) paramSchema(order int, t reflect.Type) *Schema {
+	var (
+		kind  = t.Kind().String()
+		value *Schema
+		props = map[string]*Schema{}
+	)
+	switch {
+	case t == contextType:
+		kind = t.String()
+		value = &Schema{
+			Type:                 "object",
+			Description:          "propagated context values by key name",
+			AdditionalProperties: &Schema{},
+			Nullable:             true,
+		}
+	case t == errorType:
+		kind = t.String()
+		value = g.schema(t)
+	case t.Kind() == reflect.Interface:
+		kind = t.String()
+		props["type"] = &Schema{
+			Type:        "string",
+			Description: "registered name of the concrete type",
+			Enum:        names(transmission.Implementations(t)),
+		}
+		value = &Schema{Description: "value of the type named by type"}
+	default:
+		value = g.schema(t)
+	}
+	props["order"] = &Schema{Type: "integer", Enum: []any{order}}
+	props["kind"] = &Schema{Type: "string", Enum: []any{kind}}
+	props["value"] = value
+	return &Schema{
+		Type:       "object",
+		Required:   []string{"order", "kind"},
+		Properties: props,
+		GoType:     t.String(),
+	}
+}
+
+func (g *generator) responses(contentTypes []string, results *Schema) map[string]*Response {
+	ref := func(v any) *Schema {
+		return g.schema(reflect.TypeOf(v))
+	}
+	message := &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{"message": {Type: "string"}},
+	}
+	errorKind := func(kinds ...any) map[string]*Header {
+		return map[string]*Header{
+			constant.HeaderErrorKind: {
+				Description: "class of the failure, absent for malformed requests",
+				Schema:      &Schema{Type: "string", Enum: kinds},
+			},
+		}
+	}
+	throttled := errorKind(constant.ErrorKindThrottle)
+	throttled["Retry-After"] = &Header{
+		Description: "seconds until the call may be retried",
+		Schema:      &Schema{Type: "integer"},
+	}
+	return map[string]*Response{
+		"200": {
+			Description: "results of the method",
+			Content:     content(contentTypes, results),
+		},
+		"400": {
+			Description: "the parameters do not match the method or fail validation",
+			Headers:     errorKind(constant.ErrorKindValidate, constant.ErrorKindConvert),
+			Content: content(contentTypes, &Schema{
+				OneOf: []*Schema{ref(dto.ValidateError{}), ref(dto.ConvertError{}), message},
+			}),
+		},
+		"401": {
+			Description: "the server authenticates callers and rejected the credentials",
+			Headers: map[string]*Header{
+				"WWW-Authenticate": {
+					Description: "the credentials the server asks for",
+					Schema:      &Schema{Type: "string"},
+				},
+			},
+			Content: content([]string{"application/json"}, message),
+		},
+		"409": {
+			Description: "the caller already runs a call under the same call id",
+			Content:     content([]string{"application/json"}, message),
+		},
+		"415": {
+			Description: "the content type is not supported",
+			Content:     content(contentTypes, message),
+		},
+		"422": {
+			Description: "the idempotency key was sent before with another payload",
+			Content:     content([]string{"application/json"}, message),
+		},
+		"429": {
+			Description: "a rate limit of the server throttled the call",
+			Headers:     throttled,
+			Content:     content([]string{"application/json"}, message),
+		},
+		"500": {
+			Description: "the method panicked or its results could not be converted",
+			Headers:     errorKind(constant.ErrorKindPanic, constant.ErrorKindConvert),
+			Content: content(contentTypes, &Schema{
+				OneOf: []*Schema{ref(dto.PanicError{}), ref(dto.ConvertError{})},
+			}),
+		},
+		"503": {
+			Description: "the bulkhead of the method is saturated, the method is disabled or the server is in maintenance",
+			Headers:     errorKind(constant.ErrorKindOverload, constant.ErrorKindUnavailable),
+			Content:     content([]string{"application/json"}, message),
+		},
+	}
+}
+
+func content(contentTypes []string, s *Schema) map[string]*MediaType {
+	m := make(map[string]*MediaType, len(contentTypes))
+	for _, ct := range contentTypes {
+		m[ct] = &MediaType{Schema: s}
+	}
+	return m
+}
+
+func signature(name string, ins, outs []reflect.Type, variadic bool) string {
+	in := make([]string, len(ins))
+	for i, t := range ins {
+		in[i] = t.String()
+		if variadic && i == len(ins)-1 {
+			in[i] = "..." + t.Elem().String()
+		}
+	}
+	out := make([]string, len(outs))
+	for i, t := range outs {
+		out[i] = t.String()
+	}
+	s := name + "(" + strings.Join(in, ", ") + ")"
+	switch len(out) {
+	case 0:
+	case 1:
+		s += " " + out[0]
+	default:
+		s += " (" + strings.Join(out, ", ") + ")"
+	}
+	return s
+}
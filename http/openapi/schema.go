@@ -0,0 +1,267 @@
+package openapi
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/transmission"
+	"github.com/go-kid/remote-ioc/http/validation"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+	contextType       = reflect.TypeOf((*context.Context)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+)
+
+// generator builds schemas of Go types as they appear on the wire. Named
+// struct types become components referenced by $ref.
+type generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newGenerator() *generator {
+	return &generator{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+func (g *generator) schema(t reflect.Type) *Schema {
+	if transmission.HasValueCodec(t) {
+		return &Schema{Description: "custom wire form", GoType: t.String()}
+	}
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "nanoseconds"}
+	case t.Implements(jsonMarshalerType):
+		return &Schema{GoType: t.String()}
+	case t.Implements(textMarshalerType):
+		return &Schema{Type: "string", GoType: t.String()}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32", Minimum: float(0)}
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer", Format: "int64", Minimum: float(0)}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Complex64, reflect.Complex128:
+		return &Schema{
+			Type:        "array",
+			Description: "[real, imaginary]",
+			Items:       &Schema{Type: "number"},
+			MinItems:    integer(2),
+			MaxItems:    integer(2),
+		}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Interface:
+		return g.interfaceSchema(t)
+	case reflect.Pointer:
+		return nullable(g.schema(t.Elem()))
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && !reflect.PointerTo(t.Elem()).Implements(jsonMarshalerType) &&
+			!reflect.PointerTo(t.Elem()).Implements(textMarshalerType) {
+			return &Schema{Type: "string", Format: "byte", Nullable: true}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem()), Nullable: true}
+	case reflect.Array:
+		return &Schema{Type: "array", Items: g.schema(t.Elem()), MinItems: integer(t.Len()), MaxItems: integer(t.Len())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem()), Nullable: true}
+	case reflect.Struct:
+		return g.structSchema(t)
+	}
+	return &Schema{Description: "unsupported type", GoType: t.String()}
+}
+
+// interfaceSchema describes interface values: errors travel as
+// dto.ErrorEnvelope, registered types as dto.TypedValue.
+func (g *generator) interfaceSchema(t reflect.Type) *Schema {
+	if t == errorType {
+		return nullable(g.schema(reflect.TypeOf(dto.ErrorEnvelope{})))
+	}
+	typed := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"$type":  {Type: "string", Enum: names(transmission.Implementations(t))},
+			"$value": {},
+		},
+		Required: []string{"$type", "$value"},
+		Nullable: true,
+		GoType:   t.String(),
+	}
+	if t.NumMethod() == 0 {
+		return &Schema{
+			Description: "any value, registered types are sent as {\"$type\", \"$value\"}",
+			OneOf:       []*Schema{typed, {}},
+		}
+	}
+	return typed
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return g.objectSchema(t)
+	}
+	name, ok := g.names[t]
+	if !ok {
+		name = g.componentName(t)
+		g.names[t] = name
+		// registered before the fields so recursive types end in a $ref
+		s := &Schema{}
+		g.schemas[name] = s
+		*s = *g.objectSchema(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (g *generator) objectSchema(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+		GoType:     t.String(),
+	}
+	for _, f := range transmission.WireFields(t) {
+		fs := g.schema(f.Type)
+		if f.Quoted {
+			fs = &Schema{Type: "string", Description: "quoted " + fs.Type}
+		}
+		if applyRules(fs, f.Type, f.Tag.Get(validation.Tag)) {
+			s.Required = append(s.Required, f.Name)
+		}
+		s.Properties[f.Name] = fs
+	}
+	return s
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+func (g *generator) componentName(t reflect.Type) string {
+	base := invalidNameChars.ReplaceAllString(t.String(), "_")
+	name := base
+	for i := 2; g.schemas[name] != nil; i++ {
+		name = base + "_" + strconv.Itoa(i)
+	}
+	return name
+}
+
+// applyRules maps the validate tag onto schema constraints and reports
+// whether the field is required.
+func applyRules(s *Schema, t reflect.Type, tag string) (required bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	constrainable := s.Ref == "" && len(s.AllOf) == 0
+	for _, item := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(item), "=")
+		switch name {
+		case "required":
+			required = true
+		case "min", "max", "len":
+			if constrainable {
+				limit(s, t, name, param)
+			}
+		case "email":
+			if constrainable {
+				s.Format = "email"
+			}
+		case "oneof":
+			if constrainable {
+				for _, v := range strings.Fields(param) {
+					s.Enum = append(s.Enum, enumValue(t, v))
+				}
+			}
+		}
+	}
+	return
+}
+
+func limit(s *Schema, t reflect.Type, rule, param string) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	var lower, upper **int
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if rule != "max" {
+			s.Minimum = float(n)
+		}
+		if rule != "min" {
+			s.Maximum = float(n)
+		}
+		return
+	case reflect.String:
+		lower, upper = &s.MinLength, &s.MaxLength
+	case reflect.Slice, reflect.Array:
+		lower, upper = &s.MinItems, &s.MaxItems
+	case reflect.Map:
+		lower, upper = &s.MinProperties, &s.MaxProperties
+	default:
+		return
+	}
+	if rule != "max" {
+		*lower = integer(int(n))
+	}
+	if rule != "min" {
+		*upper = integer(int(n))
+	}
+}
+
+func enumValue(t reflect.Type, v string) any {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	}
+	return v
+}
+
+// nullable marks s as accepting null, a $ref can not carry siblings in
+// OpenAPI 3.0 and is wrapped in allOf.
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{AllOf: []*Schema{s}, Nullable: true}
+	}
+	s.Nullable = true
+	return s
+}
+
+func names(values []string) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
+
+func float(v float64) *float64 { return &v }
+
+func integer(v int) *int { return &v }
@@ -0,0 +1,140 @@
+package props
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Prefix is the root of the remote-ioc configuration properties.
+const Prefix = "remote-ioc"
+
+// EnvName is the environment variable overriding a property key, such as
+// REMOTE_IOC_SERVER_ROUTE_PREFIX for remote-ioc.server.route-prefix.
+func EnvName(key string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Env overrides the fields of the struct v points to with the environment
+// variables of their keys, named by the mapstructure tags under key. Lists
+// are comma separated and maps are written as k1=v1,k2=v2. Lists of structs
+// are left to the configuration files.
+func Env(key string, v any) error {
+	_, err := env(key, reflect.ValueOf(v).Elem())
+	return err
+}
+
+func env(key string, v reflect.Value) (bool, error) {
+	t := v.Type()
+	switch {
+	case t.Kind() == reflect.Struct:
+		var set bool
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, ok := f.Tag.Lookup("mapstructure")
+			if !ok || name == "-" || !f.IsExported() {
+				continue
+			}
+			fieldSet, err := env(key+"."+name, v.Field(i))
+			if err != nil {
+				return false, err
+			}
+			set = set || fieldSet
+		}
+		return set, nil
+	case t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct:
+		elem := reflect.New(t.Elem())
+		if !v.IsNil() {
+			elem.Elem().Set(v.Elem())
+		}
+		set, err := env(key, elem.Elem())
+		if set {
+			v.Set(elem)
+		}
+		return set, err
+	}
+	name := EnvName(key)
+	raw, ok := os.LookupEnv(name)
+	if !ok {
+		return false, nil
+	}
+	if err := parse(raw, v); err != nil {
+		return false, fmt.Errorf("%s: invalid %s %q: %v", name, v.Type(), raw, err)
+	}
+	return true, nil
+}
+
+func parse(raw string, v reflect.Value) error {
+	t := v.Type()
+	if t == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.String {
+			return fmt.Errorf("not settable from the environment")
+		}
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items).Convert(t))
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String || t.Elem().Kind() != reflect.String {
+			return fmt.Errorf("not settable from the environment")
+		}
+		m := reflect.MakeMap(t)
+		for _, pair := range strings.Split(raw, ",") {
+			if pair = strings.TrimSpace(pair); pair == "" {
+				continue
+			}
+			k, val, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("%q is not a key=value pair", pair)
+			}
+			m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(k)), reflect.ValueOf(strings.TrimSpace(val)))
+		}
+		v.Set(m)
+	default:
+		return fmt.Errorf("not settable from the environment")
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package semver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Constraint is a set of version ranges. Ranges are separated by "||", the
+// comparators of a range by spaces or commas and all of them must hold.
+// Comparators are =, !=, >, >=, <, <=, ~ (patch updates), ^ (updates that
+// keep the left-most non-zero number) and x wildcards such as 1.2.x.
+// Pre-release versions only match a range naming a pre-release of the same
+// major, minor and patch, like in npm.
+type Constraint struct {
+	text   string
+	ranges [][]comparator
+}
+
+type comparator struct {
+	op string
+	v  Version
+}
+
+// ParseConstraint parses a constraint, the empty one accepts any release.
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{text: s}
+	for _, text := range strings.Split(s, "||") {
+		var r []comparator
+		for _, field := range strings.FieldsFunc(text, func(r rune) bool { return r == ' ' || r == ',' }) {
+			comparators, err := parseComparator(field)
+			if err != nil {
+				return Constraint{}, fmt.Errorf("invalid constraint %q: %v", s, err)
+			}
+			r = append(r, comparators...)
+		}
+		c.ranges = append(c.ranges, r)
+	}
+	return c, nil
+}
+
+func (c Constraint) String() string {
+	return c.text
+}
+
+// Check reports whether v satisfies the constraint.
+func (c Constraint) Check(v Version) bool {
+	for _, r := range c.ranges {
+		if c.checkRange(r, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Constraint) checkRange(r []comparator, v Version) bool {
+	preAllowed := len(v.Pre) == 0
+	for _, cmp := range r {
+		if !cmp.check(v) {
+			return false
+		}
+		if len(cmp.v.Pre) != 0 && cmp.v.release().Compare(v.release()) == 0 {
+			preAllowed = true
+		}
+	}
+	return preAllowed
+}
+
+func (c comparator) check(v Version) bool {
+	n := v.Compare(c.v)
+	switch c.op {
+	case "=":
+		return n == 0
+	case "!=":
+		return n != 0
+	case ">":
+		return n > 0
+	case ">=":
+		return n >= 0
+	case "<":
+		return n < 0
+	case "<=":
+		return n <= 0
+	}
+	return false
+}
+
+// parseComparator expands the shorthand operators into plain comparisons.
+func parseComparator(s string) ([]comparator, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", "!=", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(s, prefix) {
+			op, s = prefix, s[len(prefix):]
+			break
+		}
+	}
+	if s == "*" || s == "x" || s == "X" {
+		return nil, nil
+	}
+	v, given, err := parse(s, true)
+	if err != nil {
+		return nil, err
+	}
+	if given == 0 {
+		return nil, nil
+	}
+	// upper is the first version past the range of the given numbers
+	upper := func(level int) Version {
+		switch level {
+		case 1:
+			return Version{Major: v.Major + 1}
+		case 2:
+			return Version{Major: v.Major, Minor: v.Minor + 1}
+		}
+		return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+	lower := comparator{">=", v}
+	switch op {
+	case "~":
+		return []comparator{lower, {"<", upper(min(given, 2))}}, nil
+	case "^":
+		level := 1
+		if v.Major == 0 && given > 1 {
+			level = 2
+			if v.Minor == 0 && given > 2 {
+				level = 3
+			}
+		}
+		return []comparator{lower, {"<", upper(level)}}, nil
+	}
+	if given == 3 {
+		if op == "" {
+			op = "="
+		}
+		return []comparator{{op, v}}, nil
+	}
+	// a partial version stands for the range of its missing numbers
+	switch op {
+	case "", "=":
+		return []comparator{lower, {"<", upper(given)}}, nil
+	case "!=":
+		return nil, fmt.Errorf("!= requires a full version")
+	case ">":
+		return []comparator{{">=", upper(given)}}, nil
+	case ">=":
+		return []comparator{lower}, nil
+	case "<":
+		return []comparator{{"<", v}}, nil
+	case "<=":
+		return []comparator{{"<", upper(given)}}, nil
+	}
+	return nil, fmt.Errorf("invalid comparator %q", s)
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,116 @@
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version. A leading "v" and missing minor or patch
+// numbers are accepted when parsing, build metadata is ignored.
+type Version struct {
+	Major, Minor, Patch uint64
+	Pre                 []string
+}
+
+func Parse(s string) (Version, error) {
+	v, _, err := parse(s, false)
+	return v, err
+}
+
+// parse reads a version whose trailing numbers may be missing, or be
+// wildcards when partial is set. It returns how many numbers were given.
+func parse(s string, partial bool) (v Version, given int, err error) {
+	text := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	text, _, _ = strings.Cut(text, "+")
+	text, pre, hasPre := strings.Cut(text, "-")
+	if text == "" {
+		return v, 0, fmt.Errorf("invalid version %q", s)
+	}
+	parts := strings.Split(text, ".")
+	if len(parts) > 3 {
+		return v, 0, fmt.Errorf("invalid version %q", s)
+	}
+	numbers := []*uint64{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		if partial && (part == "x" || part == "X" || part == "*") {
+			break
+		}
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return v, 0, fmt.Errorf("invalid version %q", s)
+		}
+		*numbers[i] = n
+		given++
+	}
+	if hasPre {
+		if pre == "" || given < 3 {
+			return v, 0, fmt.Errorf("invalid version %q", s)
+		}
+		v.Pre = strings.Split(pre, ".")
+	}
+	return v, given, nil
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Pre) != 0 {
+		s += "-" + strings.Join(v.Pre, ".")
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 as v is lower than, equal to or greater than o.
+func (v Version) Compare(o Version) int {
+	if c := compareUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	switch {
+	case len(v.Pre) == 0 && len(o.Pre) == 0:
+		return 0
+	case len(v.Pre) == 0:
+		return 1
+	case len(o.Pre) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.Pre) && i < len(o.Pre); i++ {
+		if c := comparePre(v.Pre[i], o.Pre[i]); c != 0 {
+			return c
+		}
+	}
+	return compareUint(uint64(len(v.Pre)), uint64(len(o.Pre)))
+}
+
+func (v Version) release() Version {
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+}
+
+func comparePre(a, b string) int {
+	na, errA := strconv.ParseUint(a, 10, 64)
+	nb, errB := strconv.ParseUint(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil:
+		return compareUint(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/labstack/echo/v4"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// AdminConfig serves the admin routes under RouteAdmin, with which operators
+// inspect the server and take methods, or the whole server, out of service.
+type AdminConfig struct {
+	// Authenticate guards the admin routes apart from the other ones.
+	// Required.
+	Authenticate Authenticator
+	// Addr serves the admin routes on a listener of their own, such as
+	// "127.0.0.1:9090", instead of the server Addr.
+	Addr string
+}
+
+// admin holds what operators switched off.
+type admin struct {
+	mu          sync.RWMutex
+	disabled    map[string]bool
+	maintenance dto.Maintenance
+
+	e *echo.Echo
+}
+
+func newAdmin() *admin {
+	return &admin{disabled: make(map[string]bool)}
+}
+
+func methodKey(key dto.ServiceKey, method string) string {
+	return key.String() + "." + method
+}
+
+func (a *admin) enabled(key dto.ServiceKey, method string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return !a.disabled[methodKey(key, method)]
+}
+
+func (a *admin) inMaintenance() (dto.Maintenance, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.maintenance, a.maintenance.Enabled
+}
+
+// gate turns the calls of a disabled method away, and every call while the
+// server is in maintenance.
+func (a *admin) gate(key dto.ServiceKey, method string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			message := ""
+			if m, ok := a.inMaintenance(); ok {
+				message = "remote-ioc server is in maintenance"
+				if m.Message != "" {
+					message += ": " + m.Message
+				}
+			} else if !a.enabled(key, method) {
+				message = "remote method " + methodKey(key, method) + " is disabled"
+			}
+			if message == "" {
+				return next(c)
+			}
+			c.Response().Header().Set(constant.HeaderErrorKind, constant.ErrorKindUnavailable)
+			return echo.NewHTTPError(http.StatusServiceUnavailable, message)
+		}
+	}
+}
+
+// serveAdmin adds the admin routes to g, or serves them on their own
+// listener when the admin Addr is set.
+func (s *iocServer) serveAdmin(g *echo.Group) error {
+	if s.c.Admin == nil {
+		return nil
+	}
+	if s.c.Admin.Addr == "" {
+		s.adminRoutes(g.Group(constant.RouteAdmin))
+		return nil
+	}
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	s.adminRoutes(e.Group(s.c.RoutePrefix + constant.RouteAdmin))
+	listener, err := net.Listen("tcp", s.c.Admin.Addr)
+	if err != nil {
+		return fmt.Errorf("remote-ioc admin: %v", err)
+	}
+	e.Listener = listener
+	s.admin.e = e
+	go func() {
+		log.Printf("[remote-ioc] admin routes started on: %s", s.c.Admin.Addr)
+		if err := e.Start(s.c.Admin.Addr); !errors.Is(err, http.ErrServerClosed) {
+			e.Logger.Fatal(err)
+		}
+	}()
+	return nil
+}
+
+func (s *iocServer) adminRoutes(g *echo.Group) {
+	auth := authenticate(s.c.Admin.Authenticate)
+	g.GET(constant.RouteAdminRoutes, func(c echo.Context) error {
+		return c.JSON(200, s.routes())
+	}, auth)
+	g.GET(constant.RouteAdminCalls, func(c echo.Context) error {
+		return c.JSON(200, s.calls.states())
+	}, auth)
+	g.GET(constant.RouteAdminMetrics, func(c echo.Context) error {
+		return c.JSON(200, s.metrics())
+	}, auth)
+	g.GET(constant.RouteAdminMethods, func(c echo.Context) error {
+		return c.JSON(200, s.methodStates())
+	}, auth)
+	g.PUT(constant.RouteAdminMethods, func(c echo.Context) error {
+		var state dto.MethodState
+		if err := c.Bind(&state); err != nil {
+			return err
+		}
+		key := state.ServiceKey
+		if !s.exports(key, state.Method) {
+			return echo.NewHTTPError(http.StatusNotFound, "no remote method "+methodKey(key, state.Method))
+		}
+		s.admin.mu.Lock()
+		s.admin.disabled[methodKey(key, state.Method)] = !state.Enabled
+		s.admin.mu.Unlock()
+		log.Printf("[remote-ioc] remote method %s enabled: %t", methodKey(key, state.Method), state.Enabled)
+		return c.JSON(200, &state)
+	}, auth)
+	g.GET(constant.RouteAdminMaintenance, func(c echo.Context) error {
+		m, _ := s.admin.inMaintenance()
+		return c.JSON(200, &m)
+	}, auth)
+	g.PUT(constant.RouteAdminMaintenance, func(c echo.Context) error {
+		var m dto.Maintenance
+		if err := c.Bind(&m); err != nil {
+			return err
+		}
+		s.admin.mu.Lock()
+		s.admin.maintenance = m
+		s.admin.mu.Unlock()
+		log.Printf("[remote-ioc] server on %s in maintenance: %t", s.c.Addr, m.Enabled)
+		return c.JSON(200, &m)
+	}, auth)
+}
+
+func (s *iocServer) exports(key dto.ServiceKey, method string) bool {
+	for _, component := range s.cs {
+		if _, ok := component.mvm[method]; ok && component.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// routes lists the routes of the server and of the admin listener.
+func (s *iocServer) routes() []*dto.Route {
+	var routes []*dto.Route
+	for _, e := range []*echo.Echo{s.e, s.admin.e} {
+		if e == nil {
+			continue
+		}
+		for _, r := range e.Routes() {
+			routes = append(routes, &dto.Route{Method: r.Method, Path: r.Path})
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+func (s *iocServer) methodStates() []*dto.MethodState {
+	var states []*dto.MethodState
+	for _, component := range s.cs {
+		for method := range component.mvm {
+			states = append(states, &dto.MethodState{
+				ServiceKey: component.key,
+				Method:     method,
+				Enabled:    s.admin.enabled(component.key, method),
+			})
+		}
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if a, b := states[i].ServiceKey.String(), states[j].ServiceKey.String(); a != b {
+			return a < b
+		}
+		return states[i].Method < states[j].Method
+	})
+	return states
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strings"
+)
+
+// Authenticator admits a request or returns why it is rejected. A rejected
+// request is answered with 401.
+type Authenticator func(r *http.Request) error
+
+// UnauthorizedError rejects a request and asks the caller for credentials
+// with the WWW-Authenticate challenge.
+type UnauthorizedError struct {
+	Challenge string
+	Msg       string
+}
+
+func (e *UnauthorizedError) Error() string {
+	return e.Msg
+}
+
+// BasicAuth admits requests carrying the HTTP basic credentials.
+func BasicAuth(username, password string) Authenticator {
+	return func(r *http.Request) error {
+		u, p, ok := r.BasicAuth()
+		if ok && equal(u, username) && equal(p, password) {
+			return nil
+		}
+		return &UnauthorizedError{Challenge: `Basic realm="remote-ioc"`, Msg: "invalid credentials"}
+	}
+}
+
+// BearerToken admits requests carrying "Authorization: Bearer <token>".
+func BearerToken(token string) Authenticator {
+	return func(r *http.Request) error {
+		got, ok := strings.CutPrefix(r.Header.Get(echo.HeaderAuthorization), "Bearer ")
+		if ok && equal(got, token) {
+			return nil
+		}
+		return &UnauthorizedError{Challenge: "Bearer", Msg: "invalid token"}
+	}
+}
+
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// authenticate applies auth to the routes it wraps, nil admits everything.
+func authenticate(auth Authenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if auth == nil {
+			return next
+		}
+		return func(c echo.Context) error {
+			err := auth(c.Request())
+			if err == nil {
+				return next(c)
+			}
+			var unauthorized *UnauthorizedError
+			if errors.As(err, &unauthorized) && unauthorized.Challenge != "" {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, unauthorized.Challenge)
+			}
+			return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+		}
+	}
+}
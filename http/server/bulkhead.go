@@ -0,0 +1,220 @@
+package server
+
+import (
+	"context"
+	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// Bulkhead bounds the concurrent executions of a method, calls beyond it
+// wait in a bounded queue. Calls that find the queue full or wait too long
+// are answered with 503.
+type Bulkhead struct {
+	// MaxConcurrent executions, no bound when zero.
+	MaxConcurrent int `mapstructure:"max-concurrent"`
+	// MaxQueue calls wait for a slot, none when zero.
+	MaxQueue int `mapstructure:"max-queue"`
+	// QueueTimeout bounds the wait for a slot, calls wait as long as their
+	// request when zero.
+	QueueTimeout time.Duration `mapstructure:"queue-timeout"`
+}
+
+// Bulkheads isolate the methods of a server from each other. The most
+// specific bulkhead applies to a call: the one of its method, else the one
+// its service shares among its methods, else Default. They are configured by
+// service id, the services of each namespace, group and version get bulkheads
+// of their own.
+type Bulkheads struct {
+	// Default is the bulkhead of every other method, each has its own.
+	Default Bulkhead `mapstructure:"default"`
+	// Services are shared by the methods of a service id.
+	Services map[string]Bulkhead `mapstructure:"services"`
+	// Methods are keyed as "ServiceId.Method".
+	Methods map[string]Bulkhead `mapstructure:"methods"`
+}
+
+func (b *Bulkheads) empty() bool {
+	return b.Default == (Bulkhead{}) && len(b.Services) == 0 && len(b.Methods) == 0
+}
+
+// all returns the bulkheads by their property keys.
+func (b *Bulkheads) all() map[string]Bulkhead {
+	bulkheads := map[string]Bulkhead{"default": b.Default}
+	for id, bulkhead := range b.Services {
+		bulkheads["services."+id] = bulkhead
+	}
+	for key, bulkhead := range b.Methods {
+		bulkheads["methods."+key] = bulkhead
+	}
+	return bulkheads
+}
+
+type bulkhead struct {
+	c   Bulkhead
+	key dto.ServiceKey
+	// method is empty for the bulkhead of a service
+	method string
+	slots  chan struct{}
+
+	queued   atomic.Int64
+	peak     atomic.Int64
+	admitted atomic.Int64
+	rejected atomic.Int64
+	timedOut atomic.Int64
+}
+
+func newBulkhead(c Bulkhead, key dto.ServiceKey, method string) *bulkhead {
+	return &bulkhead{
+		c:      c,
+		key:    key,
+		method: method,
+		slots:  make(chan struct{}, c.MaxConcurrent),
+	}
+}
+
+// acquire waits for a slot, the returned func releases it.
+func (b *bulkhead) acquire(ctx context.Context) (func(), error) {
+	select {
+	case b.slots <- struct{}{}:
+		return b.admit(), nil
+	default:
+	}
+	if queued := b.queued.Add(1); queued > int64(b.c.MaxQueue) {
+		b.queued.Add(-1)
+		b.rejected.Add(1)
+		return nil, echo.NewHTTPError(http.StatusServiceUnavailable, b.name()+" is saturated: its queue is full")
+	}
+	defer b.queued.Add(-1)
+	var timeout <-chan time.Time
+	if b.c.QueueTimeout > 0 {
+		timer := time.NewTimer(b.c.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case b.slots <- struct{}{}:
+		return b.admit(), nil
+	case <-timeout:
+		b.timedOut.Add(1)
+		return nil, echo.NewHTTPError(http.StatusServiceUnavailable, b.name()+" is saturated: no slot freed in "+b.c.QueueTimeout.String())
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (b *bulkhead) admit() func() {
+	b.admitted.Add(1)
+	for active := int64(len(b.slots)); ; {
+		peak := b.peak.Load()
+		if active <= peak || b.peak.CompareAndSwap(peak, active) {
+			break
+		}
+	}
+	return func() {
+		<-b.slots
+	}
+}
+
+func (b *bulkhead) name() string {
+	if b.method == "" {
+		return "bulkhead of " + b.key.String()
+	}
+	return "bulkhead of " + methodKey(b.key, b.method)
+}
+
+func (b *bulkhead) state() *dto.BulkheadState {
+	return &dto.BulkheadState{
+		ServiceKey:    b.key,
+		Method:        b.method,
+		MaxConcurrent: b.c.MaxConcurrent,
+		MaxQueue:      b.c.MaxQueue,
+		Active:        len(b.slots),
+		Queued:        b.queued.Load(),
+		Peak:          b.peak.Load(),
+		Admitted:      b.admitted.Load(),
+		Rejected:      b.rejected.Load(),
+		TimedOut:      b.timedOut.Load(),
+	}
+}
+
+// bulkheads holds the bulkheads of the exported methods.
+type bulkheads struct {
+	all []*bulkhead
+	// byMethod is keyed by methodKey
+	byMethod map[string]*bulkhead
+}
+
+func newBulkheads(c Bulkheads, cs []*serviceComponent) *bulkheads {
+	var (
+		b        = &bulkheads{byMethod: make(map[string]*bulkhead)}
+		services = make(map[string]*bulkhead)
+		seen     = make(map[*bulkhead]bool)
+	)
+	for _, component := range cs {
+		serviceKey := component.key
+		for method := range component.mvm {
+			key := methodKey(serviceKey, method)
+			if _, ok := b.byMethod[key]; ok {
+				continue
+			}
+			var bh *bulkhead
+			if bc, ok := c.Methods[serviceKey.ServiceId+"."+method]; ok {
+				bh = newBulkhead(bc, serviceKey, method)
+			} else if bc, ok := c.Services[serviceKey.ServiceId]; ok {
+				if bh, ok = services[serviceKey.String()]; !ok {
+					bh = newBulkhead(bc, serviceKey, "")
+					services[serviceKey.String()] = bh
+				}
+			} else {
+				bh = newBulkhead(c.Default, serviceKey, method)
+			}
+			if bh.c.MaxConcurrent <= 0 {
+				continue
+			}
+			b.byMethod[key] = bh
+			if !seen[bh] {
+				seen[bh] = true
+				b.all = append(b.all, bh)
+			}
+		}
+	}
+	sort.Slice(b.all, func(i, j int) bool {
+		if ki, kj := b.all[i].key.String(), b.all[j].key.String(); ki != kj {
+			return ki < kj
+		}
+		return b.all[i].method < b.all[j].method
+	})
+	return b
+}
+
+// middleware holds a slot of the bulkhead of a method while it runs.
+func (b *bulkheads) middleware(key dto.ServiceKey, method string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		bh, ok := b.byMethod[methodKey(key, method)]
+		if !ok {
+			return next
+		}
+		return func(c echo.Context) error {
+			release, err := bh.acquire(c.Request().Context())
+			if err != nil {
+				c.Response().Header().Set(constant.HeaderErrorKind, constant.ErrorKindOverload)
+				return err
+			}
+			defer release()
+			return next(c)
+		}
+	}
+}
+
+func (b *bulkheads) states() []*dto.BulkheadState {
+	states := make([]*dto.BulkheadState, 0, len(b.all))
+	for _, bh := range b.all {
+		states = append(states, bh.state())
+	}
+	return states
+}
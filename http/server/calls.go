@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"sort"
+	"sync"
+	"time"
+)
+
+// inflight tracks the running invocations, and by the caller and the call
+// id it sent, so that an explicit cancel of the same caller can reach their
+// context.
+type inflight struct {
+	mu      sync.Mutex
+	calls   map[callId]*call
+	running map[*call]struct{}
+}
+
+// callId scopes the id a client sent to its caller, so that callers can
+// neither collide with nor cancel the calls of each other.
+type callId struct {
+	caller string
+	id     string
+}
+
+type call struct {
+	cancel  context.CancelFunc
+	id      string
+	key     dto.ServiceKey
+	method  string
+	caller  string
+	started time.Time
+}
+
+func newInflight() *inflight {
+	return &inflight{calls: make(map[callId]*call), running: make(map[*call]struct{})}
+}
+
+// start derives the invocation context of c from parent. The returned func
+// must be called once the invocation is over. start fails when the caller
+// already runs a call under the id of c.
+func (f *inflight) start(parent context.Context, c *call) (context.Context, func(), bool) {
+	id := callId{caller: c.caller, id: c.id}
+	f.mu.Lock()
+	if _, ok := f.calls[id]; ok && c.id != "" {
+		f.mu.Unlock()
+		return nil, nil, false
+	}
+	ctx, cancel := context.WithCancel(parent)
+	c.cancel = cancel
+	c.started = time.Now()
+	f.running[c] = struct{}{}
+	if c.id != "" {
+		f.calls[id] = c
+	}
+	f.mu.Unlock()
+	return ctx, func() {
+		f.mu.Lock()
+		delete(f.running, c)
+		if c.id != "" {
+			delete(f.calls, id)
+		}
+		f.mu.Unlock()
+		cancel()
+	}, true
+}
+
+// cancel cancels the invocation the caller runs under id and reports whether
+// there was one.
+func (f *inflight) cancel(caller, id string) bool {
+	f.mu.Lock()
+	c, ok := f.calls[callId{caller: caller, id: id}]
+	f.mu.Unlock()
+	if ok {
+		c.cancel()
+	}
+	return ok
+}
+
+// states lists the running invocations, the oldest first.
+func (f *inflight) states() []*dto.CallState {
+	now := time.Now()
+	f.mu.Lock()
+	states := make([]*dto.CallState, 0, len(f.running))
+	for c := range f.running {
+		states = append(states, &dto.CallState{
+			Id:         c.id,
+			ServiceKey: c.key,
+			Method:     c.method,
+			Caller:     c.caller,
+			Started:    c.started,
+			DurationMs: now.Sub(c.started).Milliseconds(),
+		})
+	}
+	f.mu.Unlock()
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Started.Before(states[j].Started)
+	})
+	return states
+}
@@ -9,6 +9,32 @@ type Config struct {
 	RoutePrefix            string
 	SerializationFilters   []SerializationFilter
 	DeserializationFilters []DeserializationFilter
+	// Codecs limits the wire codecs the server accepts, all registered
+	// codecs are accepted when empty.
+	Codecs []string
+	// DisablePanicStack leaves the stack trace out of the panic errors
+	// reported to clients. The booleans are pointers so that an explicit
+	// false is not taken from the properties, nil leaves them to those.
+	DisablePanicStack *bool
+	// Authenticate guards every route but the health check, nil leaves the
+	// server open.
+	Authenticate Authenticator
+	// Playground serves an invocation page on RoutePlayground. It requires
+	// Authenticate.
+	Playground *bool
+	// Registry announces the server to a service registry, nil leaves it
+	// unannounced.
+	Registry *RegistryConfig
+	// RateLimits throttle the invocations, nothing is throttled when empty.
+	RateLimits RateLimits
+	// Bulkheads bound the concurrent executions of the methods, they are
+	// unbounded when empty.
+	Bulkheads Bulkheads
+	// Idempotency replays the responses of calls sent again with the same
+	// idempotency key, calls are not deduplicated when its Store is nil.
+	Idempotency Idempotency
+	// Admin serves the admin routes, nil leaves them out.
+	Admin *AdminConfig
 }
 
 type DeserializationFilter = transmission.DeserializationFilter
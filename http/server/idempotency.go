@@ -0,0 +1,180 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/idempotency"
+	"github.com/labstack/echo/v4"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultIdempotencyTTL is how long responses are replayed when
+// Idempotency.TTL is zero.
+const DefaultIdempotencyTTL = 10 * time.Minute
+
+// Idempotency replays the completed response of a call to the calls sent
+// again with its idempotency key, such as the retries of clients. Keys are
+// scoped to the caller, as identified by RateLimits.CallerKey, and bound to
+// the payload of their first call: a call sending another payload under the
+// key is rejected with 422. Calls with a key the server is still running
+// wait for it to complete. Only responses of calls that ran the method are
+// stored, rejected calls run again.
+// Replays are answered after authentication and the admin gate, before the
+// rate limits and bulkheads: they take no tokens and no slots.
+type Idempotency struct {
+	// Store keeps the responses, calls are not deduplicated when nil.
+	Store idempotency.Store
+	TTL   time.Duration
+}
+
+// executed marks the context of a call that ran its method.
+const executed = "remote-ioc.executed"
+
+// dedup deduplicates the calls by their idempotency keys.
+type dedup struct {
+	c         Idempotency
+	callerKey func(r *http.Request) string
+
+	mu      sync.Mutex
+	running map[string]chan struct{}
+}
+
+func newDedup(c Idempotency, callerKey func(r *http.Request) string) *dedup {
+	if c.TTL <= 0 {
+		c.TTL = DefaultIdempotencyTTL
+	}
+	return &dedup{c: c, callerKey: callerKey, running: make(map[string]chan struct{})}
+}
+
+// begin returns whether the call leads the calls with its key, or the
+// channel closed when the leading one completes.
+func (d *dedup) begin(key string) (chan struct{}, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if done, ok := d.running[key]; ok {
+		return done, false
+	}
+	done := make(chan struct{})
+	d.running[key] = done
+	return done, true
+}
+
+func (d *dedup) end(key string, done chan struct{}) {
+	d.mu.Lock()
+	delete(d.running, key)
+	d.mu.Unlock()
+	close(done)
+}
+
+// middleware replays the stored response of a method call with a known
+// idempotency key.
+func (d *dedup) middleware(serviceKey dto.ServiceKey, method string, stats *methodStats) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if d.c.Store == nil {
+			return next
+		}
+		return func(c echo.Context) error {
+			id := c.Request().Header.Get(constant.HeaderIdempotencyKey)
+			if id == "" {
+				return next(c)
+			}
+			data, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return err
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(data))
+			var (
+				ctx    = c.Request().Context()
+				key    = d.callerKey(c.Request()) + " " + serviceKey.String() + "." + method + "/" + id
+				sum    = sha256.Sum256(data)
+				digest = hex.EncodeToString(sum[:])
+			)
+			for {
+				if r, ok, err := d.c.Store.Get(ctx, key); err != nil {
+					return err
+				} else if ok {
+					return replay(c, r, digest, stats)
+				}
+				done, leader := d.begin(key)
+				if !leader {
+					select {
+					case <-done:
+						continue
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}
+				// the leader before may have completed since the lookup
+				if r, ok, err := d.c.Store.Get(ctx, key); err != nil || ok {
+					d.end(key, done)
+					if err != nil {
+						return err
+					}
+					return replay(c, r, digest, stats)
+				}
+				defer d.end(key, done)
+				return d.record(c, next, key, digest)
+			}
+		}
+	}
+}
+
+// record runs the call and stores its response when the method ran.
+func (d *dedup) record(c echo.Context, next echo.HandlerFunc, key, digest string) error {
+	response := c.Response()
+	recorder := &responseRecorder{ResponseWriter: response.Writer}
+	response.Writer = recorder
+	defer func() {
+		response.Writer = recorder.ResponseWriter
+	}()
+	err := next(c)
+	if executed, _ := c.Get(executed).(bool); !executed || err != nil {
+		return err
+	}
+	r := &idempotency.Response{
+		Status:      response.Status,
+		ContentType: response.Header().Get(echo.HeaderContentType),
+		ErrorKind:   response.Header().Get(constant.HeaderErrorKind),
+		Body:        recorder.body.Bytes(),
+		Digest:      digest,
+	}
+	// the call context is done by now, a store failure only costs the replay
+	if err := d.c.Store.Put(context.Background(), key, r, d.c.TTL); err != nil {
+		log.Printf("[remote-ioc] store the response of %s: %v", key, err)
+	}
+	return nil
+}
+
+// replay answers with the stored response r, unless the call sent another
+// payload than the stored one.
+func replay(c echo.Context, r *idempotency.Response, digest string, stats *methodStats) error {
+	if r.Digest != "" && r.Digest != digest {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity,
+			"idempotency key "+c.Request().Header.Get(constant.HeaderIdempotencyKey)+" was sent with another payload")
+	}
+	stats.replayed.Add(1)
+	if r.ErrorKind != "" {
+		c.Response().Header().Set(constant.HeaderErrorKind, r.ErrorKind)
+	}
+	c.Response().Header().Set(constant.HeaderReplayed, "true")
+	return c.Blob(r.Status, r.ContentType, r.Body)
+}
+
+// responseRecorder copies the body written to the response.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"github.com/go-kid/remote-ioc/http/dto"
+	"sort"
+	"sync/atomic"
+)
+
+// methodStats counts the invocations of one exported method.
+type methodStats struct {
+	calls     atomic.Int64
+	failures  atomic.Int64
+	panics    atomic.Int64
+	throttled atomic.Int64
+	replayed  atomic.Int64
+}
+
+func (s *iocServer) metrics() []*dto.MethodMetrics {
+	var metrics []*dto.MethodMetrics
+	for _, component := range s.cs {
+		for methodName, stats := range component.stats {
+			metrics = append(metrics, &dto.MethodMetrics{
+				ServiceKey: component.key,
+				Method:     methodName,
+				Calls:      stats.calls.Load(),
+				Failures:   stats.failures.Load(),
+				Panics:     stats.panics.Load(),
+				Throttled:  stats.throttled.Load(),
+				Replayed:   stats.replayed.Load(),
+			})
+		}
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		if a, b := metrics[i].ServiceKey.String(), metrics[j].ServiceKey.String(); a != b {
+			return a < b
+		}
+		return metrics[i].Method < metrics[j].Method
+	})
+	return metrics
+}
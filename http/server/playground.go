@@ -0,0 +1,11 @@
+package server
+
+import (
+	_ "embed"
+)
+
+// playgroundPage lists the exported methods from /meta and /openapi.json and
+// invokes them through the regular method routes with the json codec.
+//
+//go:embed playground/index.html
+var playgroundPage []byte
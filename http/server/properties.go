@@ -0,0 +1,248 @@
+package server
+
+import (
+	"fmt"
+	"github.com/go-kid/remote-ioc/http/codec"
+	"github.com/go-kid/remote-ioc/http/idempotency"
+	"github.com/go-kid/remote-ioc/http/naming"
+	"github.com/go-kid/remote-ioc/http/props"
+	"github.com/samber/lo"
+	"net"
+	"net/url"
+	"time"
+)
+
+// PropertiesKey is the configuration key of the server properties.
+const PropertiesKey = props.Prefix + ".server"
+
+// Properties configure the server from the configuration files of the app,
+// such as
+//
+//	remote-ioc:
+//	  server:
+//	    addr: :8080
+//	    registry:
+//	      addr: http://registry:8500
+//
+// and from environment variables overriding them, see props.Env. The options
+// of Config given in code take precedence over both.
+type Properties struct {
+	// Addr the server listens on, such as ":8080". Required.
+	Addr        string `mapstructure:"addr"`
+	RoutePrefix string `mapstructure:"route-prefix"`
+	// Codecs the server accepts, all registered codecs when empty.
+	Codecs            []string `mapstructure:"codecs"`
+	DisablePanicStack bool     `mapstructure:"disable-panic-stack"`
+	// Playground requires auth.
+	Playground bool               `mapstructure:"playground"`
+	Auth       AuthProperties     `mapstructure:"auth"`
+	Registry   RegistryProperties `mapstructure:"registry"`
+	RateLimits RateLimits         `mapstructure:"rate-limits"`
+	Bulkheads  Bulkheads          `mapstructure:"bulkheads"`
+	// Idempotency replays the responses of retried calls.
+	Idempotency IdempotencyProperties `mapstructure:"idempotency"`
+	Admin       AdminProperties       `mapstructure:"admin"`
+}
+
+// AdminProperties serve the admin routes when Auth has credentials, which
+// are separate from the ones of the server.
+type AdminProperties struct {
+	// Addr is the own listener of the admin routes, the server Addr when
+	// empty.
+	Addr string         `mapstructure:"addr"`
+	Auth AuthProperties `mapstructure:"auth"`
+}
+
+// AuthProperties guard the server with basic credentials or with a bearer
+// token, the server is open when both are empty.
+type AuthProperties struct {
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	Token    string `mapstructure:"token"`
+}
+
+// RegistryProperties announce the server to an embedded registry served over
+// HTTP, the server is not announced when Addr is empty.
+type RegistryProperties struct {
+	// Addr is the base URL of the registry.
+	Addr    string            `mapstructure:"addr"`
+	Headers map[string]string `mapstructure:"headers"`
+	// Advertise is built from the server Addr when empty.
+	Advertise string            `mapstructure:"advertise"`
+	Zone      string            `mapstructure:"zone"`
+	Weight    int               `mapstructure:"weight"`
+	Version   string            `mapstructure:"version"`
+	Metadata  map[string]string `mapstructure:"metadata"`
+	// TTL of the registration, 30s when zero.
+	TTL time.Duration `mapstructure:"ttl"`
+}
+
+// IdempotencyProperties select the store of the responses replayed to
+// retried calls, calls are not deduplicated when Store is empty.
+type IdempotencyProperties struct {
+	// Store is memory, or file to keep the responses in Dir across restarts.
+	Store string `mapstructure:"store"`
+	Dir   string `mapstructure:"dir"`
+	// MaxEntries bounds the memory store, idempotency.DefaultMaxEntries
+	// when zero.
+	MaxEntries int `mapstructure:"max-entries"`
+	// TTL of the responses, 10m when zero.
+	TTL time.Duration `mapstructure:"ttl"`
+}
+
+// withProperties fills the options left zero in code from p and the
+// environment, then checks the result.
+func (c Config) withProperties(p *Properties) (Config, error) {
+	if p == nil {
+		p = &Properties{}
+	}
+	if err := props.Env(PropertiesKey, p); err != nil {
+		return c, err
+	}
+	c.Addr, _ = lo.Coalesce(c.Addr, p.Addr)
+	c.RoutePrefix, _ = lo.Coalesce(c.RoutePrefix, p.RoutePrefix)
+	if len(c.Codecs) == 0 {
+		c.Codecs = p.Codecs
+	}
+	if c.DisablePanicStack == nil {
+		c.DisablePanicStack = lo.ToPtr(p.DisablePanicStack)
+	}
+	if c.Playground == nil {
+		c.Playground = lo.ToPtr(p.Playground)
+	}
+	if c.Authenticate == nil {
+		auth, err := p.Auth.authenticator(PropertiesKey + ".auth")
+		if err != nil {
+			return c, err
+		}
+		c.Authenticate = auth
+	}
+	if c.Registry == nil && p.Registry.Addr != "" {
+		if _, err := url.ParseRequestURI(p.Registry.Addr); err != nil {
+			return c, fmt.Errorf("%s.registry.addr: invalid URL %q", PropertiesKey, p.Registry.Addr)
+		}
+		c.Registry = &RegistryConfig{
+			Registrar: naming.NewClient(naming.ClientConfig{Addr: p.Registry.Addr, Headers: p.Registry.Headers}),
+			Advertise: p.Registry.Advertise,
+			Zone:      p.Registry.Zone,
+			Weight:    p.Registry.Weight,
+			Version:   p.Registry.Version,
+			Metadata:  p.Registry.Metadata,
+			TTL:       p.Registry.TTL,
+		}
+	}
+	if c.RateLimits.empty() {
+		callerKey := c.RateLimits.CallerKey
+		c.RateLimits = p.RateLimits
+		c.RateLimits.CallerKey = callerKey
+	}
+	if c.Bulkheads.empty() {
+		c.Bulkheads = p.Bulkheads
+	}
+	if c.Idempotency.Store == nil {
+		store, err := p.Idempotency.store()
+		if err != nil {
+			return c, err
+		}
+		c.Idempotency.Store = store
+	}
+	if c.Idempotency.TTL == 0 {
+		c.Idempotency.TTL = p.Idempotency.TTL
+	}
+	if c.Admin == nil && (p.Admin.Addr != "" || p.Admin.Auth != (AuthProperties{})) {
+		auth, err := p.Admin.Auth.authenticator(PropertiesKey + ".admin.auth")
+		if err != nil {
+			return c, err
+		}
+		c.Admin = &AdminConfig{Authenticate: auth, Addr: p.Admin.Addr}
+	}
+	return c, c.check()
+}
+
+// authenticator builds the Authenticator of the properties under key.
+func (p AuthProperties) authenticator(key string) (Authenticator, error) {
+	switch {
+	case p.Token != "" && (p.Username != "" || p.Password != ""):
+		return nil, fmt.Errorf("%s: set either username and password or token", key)
+	case p.Token != "":
+		return BearerToken(p.Token), nil
+	case p.Username == "" && p.Password != "":
+		return nil, fmt.Errorf("%s.username is required with a password", key)
+	case p.Username != "":
+		return BasicAuth(p.Username, p.Password), nil
+	}
+	return nil, nil
+}
+
+func (p IdempotencyProperties) store() (idempotency.Store, error) {
+	switch p.Store {
+	case "":
+		return nil, nil
+	case "memory":
+		return idempotency.BoundedMemory(p.MaxEntries), nil
+	case "file":
+		if p.Dir == "" {
+			return nil, fmt.Errorf("%s.idempotency.dir is required with the file store", PropertiesKey)
+		}
+		return idempotency.File(p.Dir)
+	}
+	return nil, fmt.Errorf("%s.idempotency.store: unknown store %q, use memory or file", PropertiesKey, p.Store)
+}
+
+// check rejects invalid options by their property keys.
+func (c Config) check() error {
+	if c.Addr == "" {
+		return fmt.Errorf("%s.addr is required", PropertiesKey)
+	}
+	if _, _, err := net.SplitHostPort(c.Addr); err != nil {
+		return fmt.Errorf("%s.addr: invalid address %q: %v", PropertiesKey, c.Addr, err)
+	}
+	for _, name := range c.Codecs {
+		if _, ok := codec.Get(name); !ok {
+			return fmt.Errorf("%s.codecs: unknown codec %q, registered are %v", PropertiesKey, name, codec.Names())
+		}
+	}
+	if lo.FromPtr(c.Playground) && c.Authenticate == nil {
+		return fmt.Errorf("remote-ioc server on %s: the playground requires an Authenticate", c.Addr)
+	}
+	if r := c.Registry; r != nil {
+		if r.Registrar == nil {
+			return fmt.Errorf("remote-ioc server on %s: the registry requires a Registrar", c.Addr)
+		}
+		if r.TTL < 0 {
+			return fmt.Errorf("%s.registry.ttl: negative TTL %s", PropertiesKey, r.TTL)
+		}
+		if r.Advertise != "" {
+			if u, err := url.Parse(r.Advertise); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("%s.registry.advertise: invalid URL %q", PropertiesKey, r.Advertise)
+			}
+		}
+	}
+	for key, limit := range c.RateLimits.all() {
+		if limit.Rate < 0 || limit.Burst < 0 {
+			return fmt.Errorf("%s.rate-limits.%s: negative rate or burst", PropertiesKey, key)
+		}
+	}
+	for key, bulkhead := range c.Bulkheads.all() {
+		if bulkhead.MaxConcurrent < 0 || bulkhead.MaxQueue < 0 || bulkhead.QueueTimeout < 0 {
+			return fmt.Errorf("%s.bulkheads.%s: negative max-concurrent, max-queue or queue-timeout", PropertiesKey, key)
+		}
+	}
+	if a := c.Admin; a != nil {
+		if a.Authenticate == nil {
+			return fmt.Errorf("remote-ioc server on %s: the admin routes require an Authenticate", c.Addr)
+		}
+		if a.Addr != "" {
+			if _, _, err := net.SplitHostPort(a.Addr); err != nil {
+				return fmt.Errorf("%s.admin.addr: invalid address %q: %v", PropertiesKey, a.Addr, err)
+			}
+			if a.Addr == c.Addr {
+				return fmt.Errorf("%s.admin.addr: %q is the server addr, leave it empty to share it", PropertiesKey, a.Addr)
+			}
+		}
+	}
+	if c.Idempotency.TTL < 0 {
+		return fmt.Errorf("%s.idempotency.ttl: negative TTL %s", PropertiesKey, c.Idempotency.TTL)
+	}
+	return nil
+}
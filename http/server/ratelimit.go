@@ -0,0 +1,301 @@
+package server
+
+import (
+	"container/list"
+	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/labstack/echo/v4"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimit is a token bucket refilled with Rate tokens per second up to
+// Burst, a call takes one token. A zero Rate does not limit.
+type RateLimit struct {
+	Rate float64 `mapstructure:"rate"`
+	// Burst is the bucket size, the rate rounded up when zero.
+	Burst int `mapstructure:"burst"`
+}
+
+// RateLimits throttle the invocations, a call has to pass every limit that
+// applies to it. Throttled calls are answered with 429 and a Retry-After.
+// Services and Methods are configured by service id, the services of each
+// namespace, group and version get buckets of their own.
+type RateLimits struct {
+	// Global limits all invocations of the server.
+	Global RateLimit `mapstructure:"global"`
+	// Services limit the invocations of a service id.
+	Services map[string]RateLimit `mapstructure:"services"`
+	// Methods limit the invocations of a method, keyed as
+	// "ServiceId.Method".
+	Methods map[string]RateLimit `mapstructure:"methods"`
+	// Caller limits every caller on its own, Callers override it for
+	// some of them.
+	Caller  RateLimit            `mapstructure:"caller"`
+	Callers map[string]RateLimit `mapstructure:"callers"`
+	// MaxCallers bounds the callers tracked at once, the least recently
+	// seen one is forgotten beyond it. DefaultMaxCallers when zero.
+	MaxCallers int `mapstructure:"max-callers"`
+	// CallerKey identifies the caller of a request, the remote IP of the
+	// connection when nil. Servers behind proxies or authenticating callers
+	// should identify them better.
+	CallerKey func(r *http.Request) string `mapstructure:"-"`
+}
+
+func (l *RateLimits) empty() bool {
+	return l.Global.Rate == 0 && len(l.Services) == 0 && len(l.Methods) == 0 &&
+		l.Caller.Rate == 0 && len(l.Callers) == 0
+}
+
+// all returns the limits by their property keys.
+func (l *RateLimits) all() map[string]RateLimit {
+	limits := map[string]RateLimit{"global": l.Global, "caller": l.Caller}
+	for id, limit := range l.Services {
+		limits["services."+id] = limit
+	}
+	for key, limit := range l.Methods {
+		limits["methods."+key] = limit
+	}
+	for id, limit := range l.Callers {
+		limits["callers."+id] = limit
+	}
+	return limits
+}
+
+// bucket is a token bucket, a zero limit admits every call.
+type bucket struct {
+	limit RateLimit
+
+	mu       sync.Mutex
+	tokens   float64
+	last     time.Time
+	rejected atomic.Int64
+}
+
+func newBucket(limit RateLimit) *bucket {
+	if limit.Burst <= 0 {
+		limit.Burst = int(math.Ceil(limit.Rate))
+	}
+	return &bucket{limit: limit, tokens: float64(limit.Burst), last: time.Now()}
+}
+
+// refill adds the tokens earned since the last call. b.mu must be held.
+func (b *bucket) refill(now time.Time) {
+	if !now.After(b.last) {
+		return
+	}
+	b.tokens = math.Min(float64(b.limit.Burst), b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate)
+	b.last = now
+}
+
+// take takes a token, or returns how long until one is available.
+func (b *bucket) take(now time.Time) (time.Duration, bool) {
+	if b.limit.Rate <= 0 {
+		return 0, true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	b.rejected.Add(1)
+	return time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second)), false
+}
+
+// refund returns a token taken by a call another limit rejected.
+func (b *bucket) refund() {
+	if b.limit.Rate <= 0 {
+		return
+	}
+	b.mu.Lock()
+	b.tokens = math.Min(float64(b.limit.Burst), b.tokens+1)
+	b.mu.Unlock()
+}
+
+// full reports whether the bucket is as good as a new one.
+func (b *bucket) full(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(now)
+	return b.tokens >= float64(b.limit.Burst)
+}
+
+func (b *bucket) state(scope, key string) *dto.LimitState {
+	b.mu.Lock()
+	b.refill(time.Now())
+	tokens := b.tokens
+	b.mu.Unlock()
+	return &dto.LimitState{
+		Scope:    scope,
+		Key:      key,
+		Rate:     b.limit.Rate,
+		Burst:    b.limit.Burst,
+		Tokens:   tokens,
+		Rejected: b.rejected.Load(),
+	}
+}
+
+// DefaultMaxCallers is the number of callers tracked at once when
+// RateLimits.MaxCallers is not set.
+const DefaultMaxCallers = 10000
+
+// callerIdle is how often the buckets of the callers are swept, full ones
+// are dropped since a new bucket is the same.
+const callerIdle = time.Minute
+
+type callerBucket struct {
+	id string
+	b  *bucket
+}
+
+type limiter struct {
+	c      RateLimits
+	global *bucket
+	// services are keyed by the service keys, methods by methodKey
+	services map[string]*bucket
+	methods  map[string]*bucket
+
+	mu sync.Mutex
+	// callers hold the elements of recent, the most recently seen first
+	callers   map[string]*list.Element
+	recent    *list.List
+	lastSweep time.Time
+}
+
+func newLimiter(c RateLimits) *limiter {
+	if c.CallerKey == nil {
+		c.CallerKey = func(r *http.Request) string {
+			return echo.ExtractIPDirect()(r)
+		}
+	}
+	if c.MaxCallers <= 0 {
+		c.MaxCallers = DefaultMaxCallers
+	}
+	return &limiter{
+		c:         c,
+		global:    newBucket(c.Global),
+		services:  make(map[string]*bucket),
+		methods:   make(map[string]*bucket),
+		callers:   make(map[string]*list.Element),
+		recent:    list.New(),
+		lastSweep: time.Now(),
+	}
+}
+
+// export adds the service and method buckets of the exported components.
+func (l *limiter) export(cs []*serviceComponent) {
+	for _, component := range cs {
+		key := component.key
+		if limit, ok := l.c.Services[key.ServiceId]; ok {
+			l.services[key.String()] = newBucket(limit)
+		}
+		for method := range component.mvm {
+			if limit, ok := l.c.Methods[key.ServiceId+"."+method]; ok {
+				l.methods[methodKey(key, method)] = newBucket(limit)
+			}
+		}
+	}
+}
+
+func (l *limiter) caller(id string, now time.Time) *bucket {
+	limit, ok := l.c.Callers[id]
+	if !ok {
+		limit = l.c.Caller
+	}
+	if limit.Rate <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.lastSweep) > callerIdle {
+		for key, e := range l.callers {
+			if e.Value.(*callerBucket).b.full(now) {
+				l.recent.Remove(e)
+				delete(l.callers, key)
+			}
+		}
+		l.lastSweep = now
+	}
+	if e, ok := l.callers[id]; ok {
+		l.recent.MoveToFront(e)
+		return e.Value.(*callerBucket).b
+	}
+	if l.recent.Len() >= l.c.MaxCallers {
+		oldest := l.recent.Back()
+		l.recent.Remove(oldest)
+		delete(l.callers, oldest.Value.(*callerBucket).id)
+	}
+	b := newBucket(limit)
+	l.callers[id] = l.recent.PushFront(&callerBucket{id: id, b: b})
+	return b
+}
+
+// allow takes a token from every bucket of the call, or returns how long to
+// wait when one of them is empty.
+func (l *limiter) allow(r *http.Request, key dto.ServiceKey, method string) (time.Duration, bool) {
+	now := time.Now()
+	buckets := []*bucket{l.global, l.services[key.String()], l.methods[methodKey(key, method)], l.caller(l.c.CallerKey(r), now)}
+	for k, b := range buckets {
+		if b == nil {
+			continue
+		}
+		if wait, ok := b.take(now); !ok {
+			for _, taken := range buckets[:k] {
+				if taken != nil {
+					taken.refund()
+				}
+			}
+			return wait, false
+		}
+	}
+	return 0, true
+}
+
+// middleware throttles the invocations of a method.
+func (l *limiter) middleware(key dto.ServiceKey, method string, stats *methodStats) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			wait, ok := l.allow(c.Request(), key, method)
+			if ok {
+				return next(c)
+			}
+			stats.throttled.Add(1)
+			// Retry-After has a resolution of seconds
+			c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.Response().Header().Set(constant.HeaderErrorKind, constant.ErrorKindThrottle)
+			return echo.NewHTTPError(http.StatusTooManyRequests, "rate limit of "+methodKey(key, method)+" exceeded")
+		}
+	}
+}
+
+func (l *limiter) states() []*dto.LimitState {
+	var states []*dto.LimitState
+	if l.c.Global.Rate > 0 {
+		states = append(states, l.global.state("global", ""))
+	}
+	for id, b := range l.services {
+		states = append(states, b.state("service", id))
+	}
+	for key, b := range l.methods {
+		states = append(states, b.state("method", key))
+	}
+	l.mu.Lock()
+	for id, e := range l.callers {
+		states = append(states, e.Value.(*callerBucket).b.state("caller", id))
+	}
+	l.mu.Unlock()
+	sort.SliceStable(states, func(i, j int) bool {
+		if states[i].Scope != states[j].Scope {
+			return states[i].Scope < states[j].Scope
+		}
+		return states[i].Key < states[j].Key
+	})
+	return states
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/ioc/registry"
+	"github.com/go-kid/remote-ioc/http/discovery"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/naming"
+	"github.com/samber/lo"
+	"log"
+	"net"
+	"os"
+	"time"
+)
+
+type RegistryConfig struct {
+	Registrar naming.Registrar
+	// Advertise is the base URL clients reach the server at, such as the
+	// public address behind NAT or of a container. It is built from Addr
+	// when empty, with the host name of the machine for an unspecified host.
+	Advertise string
+	Zone      string
+	Weight    int
+	// Version is the version of the server build.
+	Version  string
+	Metadata map[string]string
+	// TTL of the registration, DefaultRegistryTTL when zero. Heartbeats
+	// renew it at a third of the TTL.
+	TTL time.Duration
+}
+
+const DefaultRegistryTTL = 30 * time.Second
+
+// Shutdown deregisters the remote servers of an app and stops them once
+// their running invocations are over or ctx is done.
+func Shutdown(ctx context.Context, a *app.App) error {
+	var errs []error
+	for _, m := range a.Registry.GetComponents(registry.Interface(new(shutdowner))) {
+		if err := m.Raw.(shutdowner).Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// announcement keeps the registration of a server alive.
+type announcement struct {
+	c    *RegistryConfig
+	reg  *naming.Registration
+	stop chan struct{}
+	done chan struct{}
+	// lease is the registration left when run is done
+	lease *naming.Lease
+}
+
+// announce prepares the registration of the server, its run starts it once
+// the server listens.
+func (s *iocServer) announce() (*announcement, error) {
+	c := s.c.Registry
+	advertise, err := advertiseAddr(c.Advertise, s.c.Addr)
+	if err != nil {
+		return nil, err
+	}
+	keys := lo.Map(s.cs, func(component *serviceComponent, _ int) dto.ServiceKey {
+		return component.key
+	})
+	ttl := c.TTL
+	if ttl <= 0 {
+		ttl = DefaultRegistryTTL
+	}
+	a := &announcement{
+		c: c,
+		reg: &naming.Registration{
+			Instance: &discovery.Instance{
+				Addr:        advertise,
+				RoutePrefix: s.c.RoutePrefix,
+				Zone:        c.Zone,
+				Weight:      c.Weight,
+				Version:     c.Version,
+				Metadata:    c.Metadata,
+			},
+			Services: keys,
+			TTL:      int((ttl + time.Second - 1) / time.Second),
+		},
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	return a, nil
+}
+
+// run registers the server and renews the lease. A lease the registry lost,
+// such as on a restart, is registered again. Each request is bounded by the
+// heartbeat interval and abandoned when the announcement is closed.
+func (a *announcement) run() {
+	defer close(a.done)
+	interval := time.Duration(a.reg.TTL) * time.Second / 3
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-a.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		reqCtx, reqCancel := context.WithTimeout(ctx, interval)
+		var err error
+		if a.lease == nil {
+			a.lease, err = a.c.Registrar.Register(reqCtx, a.reg)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("[remote-ioc] register %s failed, retrying: %v", a.reg.Instance.URL(), err)
+			}
+		} else if _, err = a.c.Registrar.KeepAlive(reqCtx, a.lease.Id); errors.Is(err, naming.ErrLeaseNotFound) {
+			log.Printf("[remote-ioc] registration of %s lost, registering again", a.reg.Instance.URL())
+			a.lease = nil
+			reqCancel()
+			continue
+		} else if err != nil && ctx.Err() == nil {
+			log.Printf("[remote-ioc] heartbeat of %s failed: %v", a.reg.Instance.URL(), err)
+		}
+		reqCancel()
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// close stops the heartbeats and deregisters the server, unless ctx is done
+// first.
+func (a *announcement) close(ctx context.Context) error {
+	close(a.stop)
+	select {
+	case <-a.done:
+	case <-ctx.Done():
+		return fmt.Errorf("deregister %s: %v", a.reg.Instance.URL(), ctx.Err())
+	}
+	if a.lease == nil {
+		return nil
+	}
+	if err := a.c.Registrar.Deregister(ctx, a.lease.Id); err != nil {
+		return fmt.Errorf("deregister %s: %v", a.reg.Instance.URL(), err)
+	}
+	return nil
+}
+
+func advertiseAddr(advertise, addr string) (string, error) {
+	if advertise != "" {
+		return advertise, nil
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("advertise address of %s: %v", addr, err)
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		if host, err = os.Hostname(); err != nil {
+			return "", fmt.Errorf("advertise address of %s: %v", addr, err)
+		}
+	}
+	return "http://" + net.JoinHostPort(host, port), nil
+}
@@ -1,24 +1,45 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/go-kid/ioc/registry"
 	"github.com/go-kid/ioc/scanner/meta"
 	"github.com/go-kid/remote-ioc/defination"
+	"github.com/go-kid/remote-ioc/http/codec"
 	"github.com/go-kid/remote-ioc/http/constant"
 	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/openapi"
+	"github.com/go-kid/remote-ioc/http/semver"
 	"github.com/go-kid/remote-ioc/http/transmission"
+	"github.com/go-kid/remote-ioc/http/validation"
 	"github.com/labstack/echo/v4"
 	"github.com/samber/lo"
+	"io"
 	"log"
+	"net"
+	"net/http"
 	"reflect"
+	"regexp"
+	"runtime/debug"
 	"sort"
+	"strings"
 )
 
 type iocServer struct {
-	c  Config
-	r  registry.Registry
-	cs []*serviceComponent
+	c     Config
+	r     registry.Registry
+	Props *Properties `prop:"remote-ioc.server"`
+	cs    []*serviceComponent
+	calls *inflight
+
+	e            *echo.Echo
+	announcement *announcement
+	limiter      *limiter
+	bulkheads    *bulkheads
+	dedup        *dedup
+	admin        *admin
 }
 
 func (s *iocServer) Order() int {
@@ -26,14 +47,41 @@ func (s *iocServer) Order() int {
 }
 
 func (s *iocServer) Run() error {
-	s.registerRemoteHandler()
+	c, err := s.c.withProperties(s.Props)
+	if err != nil {
+		return err
+	}
+	s.c = c
+	s.calls = newInflight()
+	s.limiter = newLimiter(s.c.RateLimits)
+	s.dedup = newDedup(s.c.Idempotency, s.limiter.c.CallerKey)
+	s.admin = newAdmin()
+	if err := s.registerRemoteHandler(); err != nil {
+		return err
+	}
+	s.limiter.export(s.cs)
+	s.bulkheads = newBulkheads(s.c.Bulkheads, s.cs)
+	// the announcement is ready before anything listens, not to be left
+	// serving when it fails
+	var announcement *announcement
+	if s.c.Registry != nil {
+		if announcement, err = s.announce(); err != nil {
+			return err
+		}
+	}
 
 	e := echo.New()
 	e.HideBanner = true
 	e.HidePort = true
 	{
+		auth := authenticate(s.c.Authenticate)
 		g := e.Group(s.c.RoutePrefix)
 		g.GET(constant.RouteHealth, func(c echo.Context) error {
+			if _, ok := s.admin.inMaintenance(); ok {
+				return c.JSON(http.StatusServiceUnavailable, map[string]string{
+					"status": "maintenance",
+				})
+			}
 			return c.JSON(200, map[string]string{
 				"status": "ok",
 			})
@@ -46,33 +94,138 @@ func (s *iocServer) Run() error {
 					return keys[i] < keys[j]
 				})
 				metas = append(metas, &dto.ServerInfo{
-					ServiceId: component.serviceId,
-					Methods:   keys,
+					ServiceKey: component.key,
+					Methods:    keys,
 				})
 			}
+			c.Response().Header().Set(constant.HeaderCodecs, strings.Join(s.codecs(), ","))
+			if s.c.Idempotency.Store != nil {
+				c.Response().Header().Set(constant.HeaderIdempotency, "true")
+			}
 			return c.JSON(200, metas)
-		})
+		}, auth)
+		g.GET(constant.RouteMetrics, func(c echo.Context) error {
+			return c.JSON(200, s.metrics())
+		}, auth)
+		g.GET(constant.RouteLimits, func(c echo.Context) error {
+			return c.JSON(200, s.limiter.states())
+		}, auth)
+		g.GET(constant.RouteBulkheads, func(c echo.Context) error {
+			return c.JSON(200, s.bulkheads.states())
+		}, auth)
+		doc := s.openAPI()
+		g.GET(constant.RouteOpenAPI, func(c echo.Context) error {
+			return c.JSON(200, doc)
+		}, auth)
+		if lo.FromPtr(s.c.Playground) {
+			g.GET(constant.RoutePlayground, func(c echo.Context) error {
+				return c.HTMLBlob(200, playgroundPage)
+			}, auth)
+		}
+		g.DELETE(fmt.Sprintf(constant.RouteCall, ":id"), func(c echo.Context) error {
+			if !s.calls.cancel(s.limiter.c.CallerKey(c.Request()), c.Param("id")) {
+				return echo.NewHTTPError(http.StatusNotFound, "no running call: "+c.Param("id"))
+			}
+			return c.NoContent(http.StatusNoContent)
+		}, auth)
 		for _, component := range s.cs {
+			component := component
 			for methodName, method := range component.mvm {
 				method := method
-				route := fmt.Sprintf(constant.RouteMethod, component.serviceId, methodName)
+				route := component.key.Route(methodName)
+				// replays are answered before the rate limits and bulkheads,
+				// which only the calls running the method go through
 				e.POST(route, func(c echo.Context) error {
 					return component.exportHandler(c, method)
-				})
+				}, auth,
+					s.admin.gate(component.key, methodName),
+					s.dedup.middleware(component.key, methodName, component.stats[methodName]),
+					s.limiter.middleware(component.key, methodName, component.stats[methodName]),
+					s.bulkheads.middleware(component.key, methodName))
 			}
 		}
 	}
 
+	// the main address is taken first and s.e set before the admin routes,
+	// which list its routes, are served
+	listener, err := net.Listen("tcp", s.c.Addr)
+	if err != nil {
+		return fmt.Errorf("remote-ioc server: %v", err)
+	}
+	e.Listener = listener
+	s.e = e
+	if err := s.serveAdmin(e.Group(s.c.RoutePrefix)); err != nil {
+		_ = listener.Close()
+		return err
+	}
 	go func() {
 		log.Printf("[remote-ioc] remote component started on: %s", s.c.Addr)
-		e.Logger.Fatal(
-			e.Start(s.c.Addr),
-		)
+		if err := e.Start(s.c.Addr); !errors.Is(err, http.ErrServerClosed) {
+			e.Logger.Fatal(err)
+		}
 	}()
+	if announcement != nil {
+		s.announcement = announcement
+		go announcement.run()
+	}
 	return nil
 }
 
-func (s *iocServer) registerRemoteHandler() {
+// Shutdown deregisters the server and stops it gracefully. A failed
+// deregistration does not keep the server running, the lease expires.
+func (s *iocServer) Shutdown(ctx context.Context) error {
+	var errs []error
+	if s.announcement != nil {
+		if err := s.announcement.close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if s.admin != nil && s.admin.e != nil {
+		if err := s.admin.e.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if s.e != nil {
+		if err := s.e.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *iocServer) openAPI() *openapi.Document {
+	var methods []openapi.Method
+	for _, component := range s.cs {
+		for name, method := range component.mvm {
+			methods = append(methods, openapi.Method{
+				Service: component.key,
+				Name:    name,
+				Type:    method.Type,
+			})
+		}
+	}
+	contentTypes := lo.FilterMap(s.codecs(), func(name string, _ int) (string, bool) {
+		cc, ok := codec.Get(name)
+		if !ok {
+			return "", false
+		}
+		return cc.ContentType(), true
+	})
+	return openapi.Build(&openapi.Info{
+		Title:       "remote-ioc",
+		Description: "Remote components served on " + s.c.Addr,
+		Version:     "1.0.0",
+	}, contentTypes, methods)
+}
+
+func (s *iocServer) codecs() []string {
+	if len(s.c.Codecs) != 0 {
+		return s.c.Codecs
+	}
+	return codec.Names()
+}
+
+func (s *iocServer) registerRemoteHandler() error {
 	metas := s.r.GetComponents(registry.Interface(new(defination.RemoteComponent)))
 	s.cs = lo.Map(metas, func(m *meta.Meta, index int) *serviceComponent {
 		var methods []string
@@ -87,6 +240,7 @@ func (s *iocServer) registerRemoteHandler() {
 			}
 		}
 
+		methods = lo.Without(methods, "RemoteServiceVersion", "RemoteServiceNamespace", "RemoteServiceGroup")
 		if exclude, ok := m.Raw.(defination.RemoteMethodExclude); ok {
 			methods = lo.Without(methods, exclude.ExcludeMethods()...)
 			methods = lo.Without(methods, "ExcludeMethods")
@@ -101,57 +255,223 @@ func (s *iocServer) registerRemoteHandler() {
 		})
 
 		return &serviceComponent{
-			m:         m,
-			serviceId: m.Raw.(defination.RemoteComponent).RemoteServiceId(),
+			m:          m,
+			key:        serviceKey(m.Raw),
+			calls:      s.calls,
+			callerKey:  s.limiter.c.CallerKey,
+			panicStack: !lo.FromPtr(s.c.DisablePanicStack),
+			stats: lo.MapValues(methodMap, func(reflect.Method, string) *methodStats {
+				return &methodStats{}
+			}),
 			mvm:       methodMap,
+			sFilters:  s.c.SerializationFilters,
+			dsFilters: s.c.DeserializationFilters,
+			codecs:    s.codecs(),
 		}
 	})
+	exported := make(map[dto.ServiceKey]bool)
+	for _, component := range s.cs {
+		if err := checkServiceKey(component.key); err != nil {
+			return fmt.Errorf("remote component %s: %v", component.key, err)
+		}
+		if exported[component.key] {
+			return fmt.Errorf("remote component %s is exported twice", component.key)
+		}
+		exported[component.key] = true
+	}
+	return nil
+}
+
+// serviceKey reads the identity a remote component declares.
+func serviceKey(c any) dto.ServiceKey {
+	key := dto.ServiceKey{ServiceId: c.(defination.RemoteComponent).RemoteServiceId()}
+	if v, ok := c.(defination.RemoteNamespace); ok {
+		key.Namespace = v.RemoteServiceNamespace()
+	}
+	if v, ok := c.(defination.RemoteGroup); ok {
+		key.Group = v.RemoteServiceGroup()
+	}
+	if v, ok := c.(defination.RemoteVersion); ok {
+		key.Version = v.RemoteServiceVersion()
+	}
+	return key
+}
+
+var scopeName = regexp.MustCompile(`^[A-Za-z0-9._-]*$`)
+
+// checkServiceKey rejects names that can not be told apart in routes.
+func checkServiceKey(key dto.ServiceKey) error {
+	for _, name := range []string{key.Namespace, key.Group} {
+		if !scopeName.MatchString(name) || name == constant.ScopeDefault {
+			return fmt.Errorf("invalid namespace or group %q", name)
+		}
+	}
+	if key.Version != "" {
+		if _, err := semver.Parse(key.Version); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 type serviceComponent struct {
 	m         *meta.Meta
-	serviceId string
+	key       dto.ServiceKey
 	mvm       map[string]reflect.Method
 	sFilters  []SerializationFilter
 	dsFilters []DeserializationFilter
+	codecs    []string
+	calls     *inflight
+	// callerKey identifies the callers of the running calls
+	callerKey func(r *http.Request) string
+
+	panicStack bool
+	stats      map[string]*methodStats
 }
 
-func (s *serviceComponent) exportHandler(c echo.Context, method reflect.Method) error {
-	var body = &dto.Payload{}
-	err := c.Bind(body)
+// codec resolves the codec of the request Content-Type among the ones the
+// server accepts.
+func (s *serviceComponent) codec(c echo.Context) (codec.Codec, bool) {
+	cc, ok := codec.ForContentType(c.Request().Header.Get(echo.HeaderContentType))
+	if !ok || !lo.Contains(s.codecs, cc.Name()) {
+		return nil, false
+	}
+	return cc, true
+}
+
+func (s *serviceComponent) exportHandler(c echo.Context, method reflect.Method) (err error) {
+	stats := s.stats[method.Name]
+	stats.calls.Add(1)
+	defer func() {
+		if err != nil || c.Response().Status >= http.StatusBadRequest {
+			stats.failures.Add(1)
+		}
+	}()
+
+	// the request context is also cancelled when the client disconnects
+	ctx, done, ok := s.calls.start(c.Request().Context(), &call{
+		id:     c.Request().Header.Get(constant.HeaderCallId),
+		key:    s.key,
+		method: method.Name,
+		caller: s.callerKey(c.Request()),
+	})
+	if !ok {
+		return echo.NewHTTPError(http.StatusConflict, "call already running: "+c.Request().Header.Get(constant.HeaderCallId))
+	}
+	defer done()
+	c.SetRequest(c.Request().WithContext(ctx))
+
+	cc, ok := s.codec(c)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, "unsupported content type: "+c.Request().Header.Get(echo.HeaderContentType))
+	}
+	data, err := io.ReadAll(c.Request().Body)
 	if err != nil {
 		return err
 	}
+	var body = &dto.Payload{}
+	err = cc.Unmarshal(data, body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
 	var values = make([]reflect.Value, method.Type.NumIn())
 	values[0] = s.m.Value
 	for _, p := range body.Params {
+		if p.Order < 1 || p.Order >= method.Type.NumIn() {
+			return writeError(c, cc, http.StatusBadRequest, constant.ErrorKindValidate, &dto.ValidateError{
+				Msg:              "invalid parameter order",
+				RequestParamKind: p.Kind,
+				ParamOrder:       p.Order,
+				Value:            p.Value,
+			})
+		}
 		in := method.Type.In(p.Order)
 		err = p.Validate(in)
 		if err != nil {
-			return c.JSON(400, err)
+			return writeError(c, cc, http.StatusBadRequest, constant.ErrorKindValidate, err)
 		}
 		if p.Kind == "context.Context" {
-			p.Value = c.Request().Context()
+			p.Value, err = transmission.DecodeContext(c.Request().Context(), p.Value)
+			if err != nil {
+				return writeError(c, cc, http.StatusBadRequest, constant.ErrorKindConvert, &dto.ConvertError{
+					Param: p,
+					Err:   fmt.Sprintf("parameter[%d]: %v", p.Order, err),
+				})
+			}
 		}
 		values[p.Order], err = transmission.DecryptParam(p, in, s.dsFilters)
 		if err != nil {
-			return c.JSON(400, err)
+			return writeError(c, cc, http.StatusBadRequest, constant.ErrorKindConvert, err)
 		}
 	}
-	var resultValues []reflect.Value
-	if method.Type.IsVariadic() {
-		resultValues = method.Func.CallSlice(values)
-	} else {
-		resultValues = method.Func.Call(values)
+	for i := range values {
+		if !values[i].IsValid() && method.Type.In(i) == contextType {
+			// the invocation context, even when the client left it out
+			values[i] = reflect.ValueOf(c.Request().Context())
+		}
+		if !values[i].IsValid() {
+			values[i] = reflect.Zero(method.Type.In(i))
+		}
+		if i > 0 && method.Type.In(i) != contextType {
+			if err = validation.Param(i, values[i]); err != nil {
+				return writeError(c, cc, http.StatusBadRequest, constant.ErrorKindValidate, err)
+			}
+		}
+	}
+	resultValues, panicErr := s.call(method, values)
+	c.Set(executed, true)
+	if panicErr != nil {
+		stats.panics.Add(1)
+		log.Printf("[remote-ioc] %s\n%s", panicErr.Error(), panicErr.Stack)
+		if !s.panicStack {
+			panicErr.Stack = ""
+		}
+		return writeError(c, cc, http.StatusInternalServerError, constant.ErrorKindPanic, panicErr)
 	}
 	payload, err := s.buildResponseParam(method, resultValues)
 	if err != nil {
-		return c.JSON(400, err)
+		return writeError(c, cc, http.StatusInternalServerError, constant.ErrorKindConvert, &dto.ConvertError{
+			Err: err.Error(),
+		})
+	}
+
+	return writePayload(c, cc, 200, payload)
+}
+
+// call runs the method and turns a panic into a *dto.PanicError.
+func (s *serviceComponent) call(method reflect.Method, values []reflect.Value) (results []reflect.Value, panicErr *dto.PanicError) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr = &dto.PanicError{
+				ServiceId: s.key.ServiceId,
+				Method:    method.Name,
+				Message:   fmt.Sprint(r),
+				Stack:     string(debug.Stack()),
+			}
+		}
+	}()
+	if method.Type.IsVariadic() {
+		return method.Func.CallSlice(values), nil
 	}
+	return method.Func.Call(values), nil
+}
 
-	return c.JSON(200, payload)
+// writeError answers with a dto error and marks its class for the client.
+func writeError(c echo.Context, cc codec.Codec, code int, kind string, err error) error {
+	c.Response().Header().Set(constant.HeaderErrorKind, kind)
+	return writePayload(c, cc, code, err)
 }
 
+func writePayload(c echo.Context, cc codec.Codec, code int, v any) error {
+	data, err := cc.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Blob(code, cc.ContentType(), data)
+}
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
 func (s *serviceComponent) buildResponseParam(method reflect.Method, values []reflect.Value) (*dto.Payload, error) {
 	var params []*dto.Param
 	for i := 0; i < method.Type.NumOut(); i++ {
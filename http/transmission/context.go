@@ -0,0 +1,113 @@
+package transmission
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ContextKey is a context key whose value travels with remote invocations
+// under a stable name. Both sides must create the key with the same name
+// and value type.
+type ContextKey[T any] struct {
+	name string
+}
+
+// NewContextKey creates and registers a propagated context key.
+func NewContextKey[T any](name string) *ContextKey[T] {
+	k := &ContextKey[T]{name: name}
+	RegisterContextKey[T](name, k)
+	return k
+}
+
+func (k *ContextKey[T]) Name() string {
+	return k.name
+}
+
+func (k *ContextKey[T]) WithValue(ctx context.Context, v T) context.Context {
+	return context.WithValue(ctx, k, v)
+}
+
+func (k *ContextKey[T]) Value(ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(k).(T)
+	return v, ok
+}
+
+type contextKey struct {
+	key any
+	typ reflect.Type
+}
+
+var contextKeys = struct {
+	sync.RWMutex
+	byName map[string]contextKey
+}{
+	byName: make(map[string]contextKey),
+}
+
+// RegisterContextKey propagates the values of an existing context key, such
+// as one owned by another library, under name. Values must be of type T.
+func RegisterContextKey[T any](name string, key any) {
+	if name == "" || key == nil || !reflect.TypeOf(key).Comparable() {
+		panic("transmission: register context key requires a name and a comparable key")
+	}
+	contextKeys.Lock()
+	defer contextKeys.Unlock()
+	if _, ok := contextKeys.byName[name]; ok {
+		panic(fmt.Sprintf("transmission: context key %q already registered", name))
+	}
+	contextKeys.byName[name] = contextKey{key: key, typ: reflect.TypeOf((*T)(nil)).Elem()}
+}
+
+// EncodeContext collects the values of the registered keys found in ctx.
+func EncodeContext(ctx context.Context) (map[string]any, error) {
+	if ctx == nil {
+		return nil, nil
+	}
+	contextKeys.RLock()
+	defer contextKeys.RUnlock()
+	var values map[string]any
+	for name, k := range contextKeys.byName {
+		v := ctx.Value(k.key)
+		if v == nil {
+			continue
+		}
+		encoded, err := encodeValue(reflect.ValueOf(v))
+		if err != nil {
+			return nil, fmt.Errorf("encode context value %s: %v", name, err)
+		}
+		if values == nil {
+			values = make(map[string]any)
+		}
+		values[name] = encoded
+	}
+	return values, nil
+}
+
+// DecodeContext puts the propagated values onto parent. Values of keys not
+// registered on this side are skipped.
+func DecodeContext(parent context.Context, val any) (context.Context, error) {
+	if val == nil {
+		return parent, nil
+	}
+	values, ok := val.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("context values are not an object")
+	}
+	contextKeys.RLock()
+	defer contextKeys.RUnlock()
+	ctx := parent
+	for name, item := range values {
+		k, ok := contextKeys.byName[name]
+		if !ok {
+			continue
+		}
+		v, err := convertJsonValue(k.typ, item)
+		if err != nil {
+			return nil, fmt.Errorf("decode context value %s: %v", name, err)
+		}
+		ctx = context.WithValue(ctx, k.key, v.Interface())
+	}
+	return ctx, nil
+}
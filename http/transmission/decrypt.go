@@ -2,6 +2,8 @@ package transmission
 
 import (
 	"context"
+	"encoding"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,25 +11,23 @@ import (
 	"github.com/go-kid/ioc/util/reflectx"
 	"github.com/go-kid/remote-ioc/http/dto"
 	"reflect"
+	"strings"
 )
 
 type DeserializationFilter func(p *dto.Param, inType reflect.Type) (reflect.Value, bool, error)
 
 func DecryptParam(p *dto.Param, in reflect.Type, filters []DeserializationFilter) (value reflect.Value, err error) {
-	filters = append(filters, defaultDeserializationFilters...)
+	filters = append(filters[:len(filters):len(filters)], defaultDeserializationFilters...)
 	if in.Kind() == reflect.Interface {
 		var find bool
 		for _, filter := range filters {
 			value, find, err = filter(p, in)
-			if err != nil {
-				return value, err
-			}
-			if find {
+			if err != nil || find {
 				break
 			}
 		}
-		if !find {
-			err = fmt.Errorf("decrypt interface \"%s\" faild: not found supported deserialization filter", in.Kind())
+		if err == nil && !find {
+			value, err = convertTypedValue(in, p.Type, p.Value)
 		}
 	} else {
 		value, err = convertJsonValue(in, p.Value)
@@ -46,11 +46,19 @@ var defaultDeserializationFilters = []DeserializationFilter{
 	defaultErrorDeserializationFilter,
 }
 
+// defaultContextDeserializationFilter accepts a context prepared by the
+// server or the propagated values, which are put onto a background context.
 var defaultContextDeserializationFilter DeserializationFilter = func(p *dto.Param, inType reflect.Type) (val reflect.Value, ok bool, err error) {
 	ok = p.Kind == "context.Context"
-	if ok {
-		val = reflect.ValueOf(fas.TernaryOp(p.Value == nil, context.Background(), p.Value.(context.Context)))
-		ok = true
+	if !ok {
+		return
+	}
+	ctx, isCtx := p.Value.(context.Context)
+	if !isCtx {
+		ctx, err = DecodeContext(context.Background(), p.Value)
+	}
+	if err == nil {
+		val = reflect.ValueOf(&ctx).Elem()
 	}
 	return
 }
@@ -60,25 +68,48 @@ var defaultErrorDeserializationFilter DeserializationFilter = func(p *dto.Param,
 	if !ok {
 		return
 	}
-	if p.Value == nil {
-		val = reflect.New(inType).Elem()
-	} else if s, ok := p.Value.(string); ok {
-		err = errors.New(s)
-	} else {
-		err = errors.New(": value is not a string")
+	val = reflect.New(inType).Elem()
+	var remoteErr error
+	remoteErr, err = decodeErrorValue(p.Value)
+	if err == nil && remoteErr != nil {
+		val.Set(reflect.ValueOf(remoteErr))
 	}
 	return
 }
 
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// convertJsonValue builds a value of type in from the wire data model with
+// encoding/json semantics, it is the reverse of encodeValue.
 func convertJsonValue(in reflect.Type, val any) (value reflect.Value, err error) {
-	value = reflectx.New(in)
 	if val == nil {
+		return reflect.Zero(in), nil
+	}
+	if c, ok := lookupValueCodec(in); ok {
+		return c.decodeValue(val, in)
+	}
+	value = reflectx.New(in)
+	if value.Type().Implements(jsonUnmarshalerType) {
+		var raw []byte
+		raw, err = json.Marshal(val)
+		if err != nil {
+			return
+		}
+		err = value.Interface().(json.Unmarshaler).UnmarshalJSON(raw)
+		if err != nil {
+			err = fmt.Errorf(": %v", err)
+			return
+		}
 		value = fas.TernaryOp(in.Kind() == reflect.Pointer, value, value.Elem())
 		return
 	}
-	if msl, ok := value.Interface().(json.Unmarshaler); ok {
-		err = msl.UnmarshalJSON([]byte(fmt.Sprintf("%#v", val)))
+	if s, ok := val.(string); ok && value.Type().Implements(textUnmarshalerType) {
+		err = value.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
 		if err != nil {
+			err = fmt.Errorf(": %v", err)
 			return
 		}
 		value = fas.TernaryOp(in.Kind() == reflect.Pointer, value, value.Elem())
@@ -93,20 +124,29 @@ func convertJsonValue(in reflect.Type, val any) (value reflect.Value, err error)
 		} else {
 			err = errors.New(": value is not a string")
 		}
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		if f, ok := val.(float64); ok {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = parseInt(val, in); err == nil {
 			value = value.Elem()
-			value.SetInt(int64(f))
-		} else {
-			err = errors.New(": value is not a number")
+			value.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		if u, err = parseUint(val, in); err == nil {
+			value = value.Elem()
+			value.SetUint(u)
 		}
 	case reflect.Float32, reflect.Float64:
-		if f, ok := val.(float64); ok {
+		var f float64
+		if f, err = parseFloat(val, in); err == nil {
 			value = value.Elem()
 			value.SetFloat(f)
-		} else {
-			err = errors.New(": value is not a number")
+		}
+	case reflect.Complex64, reflect.Complex128:
+		var c complex128
+		if c, err = parseComplex(val, in); err == nil {
+			value = value.Elem()
+			value.SetComplex(c)
 		}
 	case reflect.Bool:
 		if b, ok := val.(bool); ok {
@@ -118,15 +158,14 @@ func convertJsonValue(in reflect.Type, val any) (value reflect.Value, err error)
 	case reflect.Struct:
 		if vm, ok := val.(map[string]any); ok {
 			value = value.Elem()
-			err = reflectx.ForEachFieldV2(in, value, true, func(field reflect.StructField, value reflect.Value) error {
-				key := field.Tag.Get("json")
-				v, err := convertJsonValue(field.Type, vm[key])
-				if err != nil {
-					return fmt.Errorf(".%s%v", key, err)
-				}
-				value.Set(v)
-				return nil
-			})
+			err = convertStruct(in, value, vm)
+		} else {
+			err = errors.New(": value is not a object")
+		}
+	case reflect.Map:
+		if vm, ok := val.(map[string]any); ok {
+			value = value.Elem()
+			err = convertMap(in, value, vm)
 		} else {
 			err = errors.New(": value is not a object")
 		}
@@ -134,9 +173,9 @@ func convertJsonValue(in reflect.Type, val any) (value reflect.Value, err error)
 		if anies, ok := val.([]any); ok {
 			value = value.Elem()
 			nt := in.Elem()
+			// extra elements are dropped, as encoding/json does
 			if len(anies) > value.Len() {
-				err = errors.New(": array index out of range")
-				return
+				anies = anies[:value.Len()]
 			}
 			for i, item := range anies {
 				var v reflect.Value
@@ -151,10 +190,19 @@ func convertJsonValue(in reflect.Type, val any) (value reflect.Value, err error)
 			err = errors.New(": value is not an array")
 		}
 	case reflect.Slice:
+		if in.Elem().Kind() == reflect.Uint8 && !reflect.PointerTo(in.Elem()).Implements(jsonUnmarshalerType) &&
+			!reflect.PointerTo(in.Elem()).Implements(textUnmarshalerType) {
+			var b []byte
+			if b, err = parseBytes(val); err == nil {
+				value = value.Elem()
+				value.SetBytes(b)
+			}
+			return
+		}
 		if anies, ok := val.([]any); ok {
 			value = value.Elem()
 			nt := in.Elem()
-			var values []reflect.Value
+			var values = make([]reflect.Value, 0, len(anies))
 			for i, item := range anies {
 				var v reflect.Value
 				v, err = convertJsonValue(nt, item)
@@ -164,7 +212,7 @@ func convertJsonValue(in reflect.Type, val any) (value reflect.Value, err error)
 				}
 				values = append(values, v)
 			}
-			value.Set(reflect.Append(value, values...))
+			value.Set(reflect.Append(reflect.MakeSlice(in, 0, len(values)), values...))
 		} else {
 			err = errors.New(": value is not an array")
 		}
@@ -176,15 +224,199 @@ func convertJsonValue(in reflect.Type, val any) (value reflect.Value, err error)
 			return
 		}
 		value.Elem().Set(v)
-	case reflect.Interface: //only handle the interface of error
-		if s, ok := val.(string); ok {
-			value = value.Elem()
-			value.Set(reflect.ValueOf(errors.New(s)))
-		} else {
-			err = errors.New(": value is not a string")
+	case reflect.Interface:
+		value = value.Elem()
+		switch {
+		case in == errorType:
+			var remoteErr error
+			if remoteErr, err = decodeErrorValue(val); err == nil && remoteErr != nil {
+				value.Set(reflect.ValueOf(remoteErr))
+			}
+		default:
+			var v reflect.Value
+			v, err = convertTypedValue(in, "", val)
+			if err == nil {
+				value.Set(v)
+			}
 		}
 	default:
 		err = errors.New(": unsupported type")
 	}
 	return
 }
+
+// convertTypedValue decodes an interface value from its registered type name.
+// The name comes from dto.Param.Type for parameters and from a dto.TypedValue
+// for nested values. Untyped values are only accepted by empty interfaces,
+// which then hold what encoding/json would store.
+func convertTypedValue(in reflect.Type, typeName string, val any) (reflect.Value, error) {
+	if typeName == "" {
+		if m, ok := val.(map[string]any); ok && len(m) == 2 {
+			if name, ok := m["$type"].(string); ok {
+				if item, ok := m["$value"]; ok {
+					typeName, val = name, item
+				}
+			}
+		}
+	}
+	if typeName == "" {
+		if val == nil {
+			return reflect.Zero(in), nil
+		}
+		if in.NumMethod() != 0 {
+			return reflect.Value{}, fmt.Errorf(": value of %s has no registered type", in)
+		}
+		value := reflect.New(in).Elem()
+		value.Set(reflect.ValueOf(plainValue(val)))
+		return value, nil
+	}
+	t, err := concreteType(typeName, in)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	v, err := convertJsonValue(t, val)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	value := reflect.New(in).Elem()
+	value.Set(v)
+	return value, nil
+}
+
+func convertStruct(in reflect.Type, value reflect.Value, vm map[string]any) error {
+	fields := cachedFields(in)
+	for key, item := range vm {
+		f, ok := lookupField(fields, key)
+		if !ok {
+			continue
+		}
+		if f.quoted {
+			var err error
+			item, err = unquoteValue(item)
+			if err != nil {
+				return fmt.Errorf(".%s%v", f.name, err)
+			}
+		}
+		v, err := convertJsonValue(f.typ, item)
+		if err != nil {
+			return fmt.Errorf(".%s%v", f.name, err)
+		}
+		fv, ok := fieldByIndex(value, f.index, true)
+		if !ok {
+			return fmt.Errorf(".%s: can not set embedded pointer to unexported struct", f.name)
+		}
+		fv.Set(v)
+	}
+	return nil
+}
+
+func convertMap(in reflect.Type, value reflect.Value, vm map[string]any) error {
+	value.Set(reflect.MakeMapWithSize(in, len(vm)))
+	for key, item := range vm {
+		k, err := convertMapKey(in.Key(), key)
+		if err != nil {
+			return fmt.Errorf(".%s%v", key, err)
+		}
+		v, err := convertJsonValue(in.Elem(), item)
+		if err != nil {
+			return fmt.Errorf(".%s%v", key, err)
+		}
+		value.SetMapIndex(k, v)
+	}
+	return nil
+}
+
+func convertMapKey(in reflect.Type, key string) (reflect.Value, error) {
+	if in.Kind() == reflect.String {
+		return reflect.ValueOf(key).Convert(in), nil
+	}
+	if reflect.PointerTo(in).Implements(textUnmarshalerType) {
+		k := reflect.New(in)
+		err := k.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key))
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf(": %v", err)
+		}
+		return k.Elem(), nil
+	}
+	switch in.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !isDecimal(key) {
+			return reflect.Value{}, fmt.Errorf(": map key %q is not an integer", key)
+		}
+		return convertJsonValue(in, json.Number(key))
+	}
+	return reflect.Value{}, fmt.Errorf(": unsupported map key type %s", in)
+}
+
+// unquoteValue reverses the json ",string" option.
+func unquoteValue(item any) (any, error) {
+	s, ok := item.(string)
+	if !ok {
+		if item == nil {
+			return nil, nil
+		}
+		return nil, errors.New(": value is not a quoted string")
+	}
+	var v any
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.UseNumber()
+	err := decoder.Decode(&v)
+	if err != nil {
+		return nil, fmt.Errorf(": invalid quoted value %q", s)
+	}
+	return v, nil
+}
+
+func parseBytes(val any) ([]byte, error) {
+	switch b := val.(type) {
+	case []byte:
+		return b, nil
+	case string:
+		raw, err := base64.StdEncoding.DecodeString(b)
+		if err != nil {
+			return nil, fmt.Errorf(": value is not base64: %v", err)
+		}
+		return raw, nil
+	}
+	return nil, errors.New(": value is not a base64 string")
+}
+
+func parseComplex(val any, in reflect.Type) (complex128, error) {
+	pair, ok := val.([]any)
+	if !ok || len(pair) != 2 {
+		return 0, errors.New(": value is not a [real, imaginary] pair")
+	}
+	part := reflect.TypeOf(float64(0))
+	if in.Kind() == reflect.Complex64 {
+		part = reflect.TypeOf(float32(0))
+	}
+	re, err := parseFloat(pair[0], part)
+	if err != nil {
+		return 0, err
+	}
+	im, err := parseFloat(pair[1], part)
+	if err != nil {
+		return 0, err
+	}
+	return complex(re, im), nil
+}
+
+// plainValue converts a wire value to what encoding/json stores in an empty
+// interface, numbers become float64.
+func plainValue(val any) any {
+	switch v := val.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case []any:
+		for i := range v {
+			v[i] = plainValue(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = plainValue(v[k])
+		}
+	}
+	return val
+}
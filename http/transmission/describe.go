@@ -0,0 +1,56 @@
+package transmission
+
+import (
+	"reflect"
+	"sort"
+)
+
+// WireField describes a struct field under the name it is sent with.
+type WireField struct {
+	Name      string
+	Type      reflect.Type
+	Tag       reflect.StructTag
+	OmitEmpty bool
+	// Quoted fields carry their value as a string, see the json ",string"
+	// option.
+	Quoted bool
+}
+
+// WireFields returns the fields of the struct type t in the order they are
+// sent, embedded structs are flattened like encoding/json does.
+func WireFields(t reflect.Type) []WireField {
+	fields := cachedFields(t)
+	out := make([]WireField, len(fields))
+	for i, f := range fields {
+		out[i] = WireField{
+			Name:      f.name,
+			Type:      f.typ,
+			Tag:       f.tag,
+			OmitEmpty: f.omitEmpty,
+			Quoted:    f.quoted,
+		}
+	}
+	return out
+}
+
+// HasValueCodec reports whether a registered value codec replaces the wire
+// form of t.
+func HasValueCodec(t reflect.Type) bool {
+	_, ok := lookupValueCodec(t)
+	return ok
+}
+
+// Implementations returns the sorted names of the registered types that can
+// be sent as a value of the interface type in.
+func Implementations(in reflect.Type) []string {
+	types.RLock()
+	defer types.RUnlock()
+	var names []string
+	for name, t := range types.byName {
+		if t.AssignableTo(in) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
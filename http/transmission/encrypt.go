@@ -1,14 +1,21 @@
 package transmission
 
 import (
+	"context"
+	"encoding"
+	"encoding/json"
 	"fmt"
 	"github.com/go-kid/remote-ioc/http/dto"
 	"reflect"
+	"strconv"
 )
 
 func EncryptParam(order int, paramType reflect.Type, value any, filters []SerializationFilter) (*dto.Param, error) {
-	var kind = paramType.Kind().String()
-	filters = append(filters, defaultSerializationFilters...)
+	var (
+		kind     = paramType.Kind().String()
+		typeName string
+	)
+	filters = append(filters[:len(filters):len(filters)], defaultSerializationFilters...)
 	if paramType.Kind() == reflect.Interface {
 		kind = paramType.String()
 		var find bool
@@ -23,13 +30,28 @@ func EncryptParam(order int, paramType reflect.Type, value any, filters []Serial
 				break
 			}
 		}
-		if !find {
-			return nil, fmt.Errorf("encrypt interface \"%s\" faild: not found supported serialization filter", kind)
+		if !find && value != nil {
+			name, ok := registeredName(reflect.TypeOf(value))
+			if !ok {
+				return nil, fmt.Errorf("encrypt interface \"%s\" faild: not found supported serialization filter or registered type for %T", kind, value)
+			}
+			encoded, err := encodeValue(reflect.ValueOf(value))
+			if err != nil {
+				return nil, fmt.Errorf("encrypt parameter[%d]%s", order, err)
+			}
+			typeName, value = name, encoded
+		}
+	} else {
+		var err error
+		value, err = encodeValue(reflect.ValueOf(value))
+		if err != nil {
+			return nil, fmt.Errorf("encrypt parameter[%d]%s", order, err)
 		}
 	}
 	return &dto.Param{
 		Order: order,
 		Kind:  kind,
+		Type:  typeName,
 		Value: value,
 	}, nil
 }
@@ -43,8 +65,12 @@ var defaultSerializationFilters = []SerializationFilter{
 
 var defaultContextSerializationFilter SerializationFilter = func(inType string, value any) (val2 any, ok bool, err error) {
 	ok = inType == "context.Context"
-	if ok {
-		val2 = nil
+	if ok && value != nil {
+		var values map[string]any
+		values, err = EncodeContext(value.(context.Context))
+		if values != nil {
+			val2 = values
+		}
 	}
 	return
 }
@@ -55,7 +81,182 @@ var defaultErrorSerializationFilter SerializationFilter = func(inType string, va
 		return
 	}
 	if value != nil {
-		val2 = value.(error).Error()
+		val2 = EncodeError(value.(error))
 	}
 	return
 }
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// encodeValue turns v into the wire data model with encoding/json semantics.
+// Types encoding/json can not represent are mapped as well: complex numbers
+// become a [real, imaginary] pair.
+func encodeValue(v reflect.Value) (any, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	t := v.Type()
+	if c, ok := lookupValueCodec(t); ok {
+		return c.encodeValue(v)
+	}
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		if t.Kind() == reflect.Pointer && v.IsNil() {
+			return nil, nil
+		}
+		return v.Interface(), nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return json.Number(strconv.FormatFloat(v.Float(), 'g', -1, t.Bits())), nil
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		bits := t.Bits() / 2
+		return []any{
+			json.Number(strconv.FormatFloat(real(c), 'g', -1, bits)),
+			json.Number(strconv.FormatFloat(imag(c), 'g', -1, bits)),
+		}, nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if t == errorType {
+			return EncodeError(v.Interface().(error)), nil
+		}
+		if name, ok := registeredName(v.Elem().Type()); ok {
+			item, err := encodeValue(v.Elem())
+			if err != nil {
+				return nil, err
+			}
+			return &dto.TypedValue{Type: name, Value: item}, nil
+		}
+		if t.NumMethod() != 0 {
+			return nil, fmt.Errorf(": type %s of %s is not registered", v.Elem().Type(), t)
+		}
+		return encodeValue(v.Elem())
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return encodeValue(v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 && !reflect.PointerTo(t.Elem()).Implements(jsonMarshalerType) &&
+			!reflect.PointerTo(t.Elem()).Implements(textMarshalerType) {
+			return v.Bytes(), nil
+		}
+		fallthrough
+	case reflect.Array:
+		arr := make([]any, v.Len())
+		for i := range arr {
+			item, err := encodeValue(v.Index(i))
+			if err != nil {
+				return nil, fmt.Errorf(".$%d%s", i+1, err)
+			}
+			arr[i] = item
+		}
+		return arr, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := encodeMapKey(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			item, err := encodeValue(iter.Value())
+			if err != nil {
+				return nil, fmt.Errorf(".%s%s", key, err)
+			}
+			m[key] = item
+		}
+		return m, nil
+	case reflect.Struct:
+		m := make(map[string]any)
+		for _, f := range cachedFields(t) {
+			fv, ok := fieldByIndex(v, f.index, false)
+			if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+				continue
+			}
+			item, err := encodeValue(fv)
+			if err != nil {
+				return nil, fmt.Errorf(".%s%s", f.name, err)
+			}
+			if f.quoted && item != nil {
+				item, err = quoteValue(item)
+				if err != nil {
+					return nil, fmt.Errorf(".%s%s", f.name, err)
+				}
+			}
+			m[f.name] = item
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf(": unsupported type %s", t)
+}
+
+func encodeMapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Pointer && k.IsNil() {
+			return "", nil
+		}
+		b, err := tm.MarshalText()
+		return string(b), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf(": unsupported map key type %s", k.Type())
+}
+
+// quoteValue applies the json ",string" option: the encoded scalar is sent
+// as a JSON string holding its JSON text.
+func quoteValue(item any) (any, error) {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Complex64, reflect.Complex128:
+		return v.Complex() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,219 @@
+package transmission
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"reflect"
+	"sync"
+)
+
+// CodedError is implemented by errors that carry a machine readable code.
+type CodedError interface {
+	ErrorCode() string
+}
+
+// DetailedError is implemented by errors that carry structured details.
+type DetailedError interface {
+	ErrorDetails() map[string]any
+}
+
+type errorEntry struct {
+	sentinel error
+	typ      reflect.Type
+}
+
+var remoteErrors = struct {
+	sync.RWMutex
+	byName     map[string]errorEntry
+	bySentinel map[error]string
+	byType     map[reflect.Type]string
+}{
+	byName:     make(map[string]errorEntry),
+	bySentinel: make(map[error]string),
+	byType:     make(map[reflect.Type]string),
+}
+
+// RegisterError registers a sentinel error value, a remote error matching
+// it is rebuilt as the very same value so that errors.Is holds.
+func RegisterError(name string, sentinel error) {
+	if name == "" || sentinel == nil || !reflect.TypeOf(sentinel).Comparable() {
+		panic("transmission: register error requires a name and a comparable error value")
+	}
+	remoteErrors.Lock()
+	defer remoteErrors.Unlock()
+	checkErrorName(name)
+	remoteErrors.byName[name] = errorEntry{sentinel: sentinel}
+	remoteErrors.bySentinel[sentinel] = name
+}
+
+// RegisterErrorType registers the concrete type of sample, remote errors of
+// that type are rebuilt from their exported fields so that errors.As holds.
+func RegisterErrorType(name string, sample error) {
+	if name == "" || sample == nil {
+		panic("transmission: register error type requires a name and a sample error")
+	}
+	t := reflect.TypeOf(sample)
+	remoteErrors.Lock()
+	defer remoteErrors.Unlock()
+	checkErrorName(name)
+	remoteErrors.byName[name] = errorEntry{typ: t}
+	remoteErrors.byType[t] = name
+}
+
+func checkErrorName(name string) {
+	if _, ok := remoteErrors.byName[name]; ok {
+		panic(fmt.Sprintf("transmission: error name %q already registered", name))
+	}
+}
+
+// EncodeError builds the envelope of err and its cause chain.
+func EncodeError(err error) *dto.ErrorEnvelope {
+	if err == nil {
+		return nil
+	}
+	env := &dto.ErrorEnvelope{
+		Message: err.Error(),
+		Type:    reflect.TypeOf(err).String(),
+	}
+	if coded, ok := err.(CodedError); ok {
+		env.Code = coded.ErrorCode()
+	}
+	if detailed, ok := err.(DetailedError); ok {
+		if details, e := encodeValue(reflect.ValueOf(detailed.ErrorDetails())); e == nil && details != nil {
+			env.Details = details.(map[string]any)
+		}
+	}
+
+	if remoteErr, ok := err.(*dto.RemoteError); ok {
+		// relay an error received from another peer as it was sent
+		env.Type, env.Code, env.Details = remoteErr.Type, remoteErr.Code, remoteErr.Details
+		env.Cause = EncodeError(remoteErr.Unwrap())
+		return env
+	}
+
+	remoteErrors.RLock()
+	sentinel, isSentinel := "", false
+	if reflect.TypeOf(err).Comparable() {
+		sentinel, isSentinel = remoteErrors.bySentinel[err]
+	}
+	typeName, isType := remoteErrors.byType[reflect.TypeOf(err)]
+	remoteErrors.RUnlock()
+	switch {
+	case isSentinel:
+		env.Type = sentinel
+	case isType:
+		if value, e := encodeValue(reflect.ValueOf(err)); e == nil {
+			env.Type, env.Value = typeName, value
+		}
+	}
+
+	if multi, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, cause := range multi.Unwrap() {
+			if cause != nil {
+				env.Causes = append(env.Causes, EncodeError(cause))
+			}
+		}
+		return env
+	}
+	env.Cause = EncodeError(errors.Unwrap(err))
+	return env
+}
+
+// DecodeError rebuilds an error from its envelope. Registered sentinels and
+// types are restored as such, anything else becomes a *dto.RemoteError.
+func DecodeError(env *dto.ErrorEnvelope) error {
+	if env == nil {
+		return nil
+	}
+	cause := DecodeError(env.Cause)
+	if len(env.Causes) > 0 {
+		causes := make([]error, len(env.Causes))
+		for i, c := range env.Causes {
+			causes[i] = DecodeError(c)
+		}
+		cause = errors.Join(causes...)
+	}
+	remoteErrors.RLock()
+	entry, ok := remoteErrors.byName[env.Type]
+	remoteErrors.RUnlock()
+	if ok {
+		if entry.sentinel != nil {
+			return entry.sentinel
+		}
+		if v, err := convertJsonValue(entry.typ, env.Value); err == nil {
+			if rebuilt, ok := v.Interface().(error); ok && rebuilt != nil {
+				return rebuilt
+			}
+		}
+	}
+	return dto.NewRemoteError(env, cause)
+}
+
+// decodeErrorValue accepts an error envelope and, from older peers, a bare
+// message string.
+func decodeErrorValue(val any) (error, error) {
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return errors.New(v), nil
+	case map[string]any:
+		env, err := decodeEnvelope(v)
+		if err != nil {
+			return nil, err
+		}
+		return DecodeError(env), nil
+	}
+	return nil, errors.New(": value is not an error")
+}
+
+// decodeEnvelope reads an envelope without passing its value through an
+// empty interface, which would turn numbers into float64.
+func decodeEnvelope(m map[string]any) (*dto.ErrorEnvelope, error) {
+	env := &dto.ErrorEnvelope{Value: m["value"]}
+	for key, dst := range map[string]*string{"code": &env.Code, "message": &env.Message, "type": &env.Type} {
+		if v, ok := m[key]; ok && v != nil {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf(".%s: value is not a string", key)
+			}
+			*dst = s
+		}
+	}
+	if details, ok := m["details"]; ok && details != nil {
+		v, err := convertJsonValue(reflect.TypeOf(env.Details), details)
+		if err != nil {
+			return nil, fmt.Errorf(".details%v", err)
+		}
+		env.Details = v.Interface().(map[string]any)
+	}
+	if cause, ok := m["cause"]; ok && cause != nil {
+		cm, ok := cause.(map[string]any)
+		if !ok {
+			return nil, errors.New(".cause: value is not an object")
+		}
+		var err error
+		if env.Cause, err = decodeEnvelope(cm); err != nil {
+			return nil, fmt.Errorf(".cause%v", err)
+		}
+	}
+	if causes, ok := m["causes"]; ok && causes != nil {
+		list, ok := causes.([]any)
+		if !ok {
+			return nil, errors.New(".causes: value is not an array")
+		}
+		for i, cause := range list {
+			cm, ok := cause.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf(".causes[%d]: value is not an object", i)
+			}
+			c, err := decodeEnvelope(cm)
+			if err != nil {
+				return nil, fmt.Errorf(".causes[%d]%v", i, err)
+			}
+			env.Causes = append(env.Causes, c)
+		}
+	}
+	return env, nil
+}
@@ -0,0 +1,126 @@
+package transmission
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+// numberText returns the decimal text of a wire number. Numbers arrive as
+// json.Number from the codecs, strings are numbers only for the fields with
+// the ",string" option, which are unquoted before.
+func numberText(val any) (string, bool) {
+	switch n := val.(type) {
+	case json.Number:
+		return n.String(), true
+	case float64:
+		return strconv.FormatFloat(n, 'g', -1, 64), true
+	case int64:
+		return strconv.FormatInt(n, 10), true
+	case uint64:
+		return strconv.FormatUint(n, 10), true
+	}
+	return "", false
+}
+
+// maxNumberText bounds the length of the integers written with a fraction
+// or an exponent, far beyond the digits of any integer kind.
+const maxNumberText = 128
+
+// integerText normalizes integral numbers written with a fraction or an
+// exponent, such as 1e3 or 2.0, to plain decimal text. Numbers beyond 64
+// bits are rejected before they are expanded.
+func integerText(val any, in reflect.Type) (string, error) {
+	s, ok := numberText(val)
+	if !ok {
+		return "", fmt.Errorf(": value is not a number")
+	}
+	if isDecimal(s) {
+		return s, nil
+	}
+	if len(s) > maxNumberText {
+		return "", fmt.Errorf(": value %s overflows %s", abbrev(s), in.Kind())
+	}
+	f, _, err := big.ParseFloat(s, 10, 256, big.ToNearestEven)
+	if err != nil {
+		return "", fmt.Errorf(": value %q is not a number", s)
+	}
+	if !f.IsInt() {
+		return "", fmt.Errorf(": value %s is not an integer", s)
+	}
+	if f.MantExp(nil) > 64 {
+		return "", fmt.Errorf(": value %s overflows %s", s, in.Kind())
+	}
+	i, _ := f.Int(nil)
+	return i.String(), nil
+}
+
+// isDecimal reports whether s is a plain decimal integer.
+func isDecimal(s string) bool {
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// abbrev shortens the numbers quoted in errors.
+func abbrev(s string) string {
+	if len(s) > 32 {
+		return s[:32] + "..."
+	}
+	return s
+}
+
+func parseInt(val any, in reflect.Type) (int64, error) {
+	s, err := integerText(val, in)
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.ParseInt(s, 10, in.Bits())
+	if err != nil {
+		return 0, fmt.Errorf(": value %s overflows %s", abbrev(s), in.Kind())
+	}
+	return i, nil
+}
+
+func parseUint(val any, in reflect.Type) (uint64, error) {
+	s, err := integerText(val, in)
+	if err != nil {
+		return 0, err
+	}
+	u, err := strconv.ParseUint(s, 10, in.Bits())
+	if err != nil {
+		return 0, fmt.Errorf(": value %s overflows %s", abbrev(s), in.Kind())
+	}
+	return u, nil
+}
+
+func parseFloat(val any, in reflect.Type) (float64, error) {
+	s, ok := numberText(val)
+	if !ok {
+		return 0, fmt.Errorf(": value is not a number")
+	}
+	f, err := strconv.ParseFloat(s, in.Bits())
+	if err != nil {
+		if math.IsInf(f, 0) {
+			return 0, fmt.Errorf(": value %s overflows %s", s, in.Kind())
+		}
+		return 0, fmt.Errorf(": value %q is not a number", s)
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		// strconv reads NaN and Inf, which encoding/json has no numbers for
+		return 0, fmt.Errorf(": value %q is not a number", s)
+	}
+	return f, nil
+}
@@ -0,0 +1,79 @@
+package transmission
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var types = struct {
+	sync.RWMutex
+	byName map[string]reflect.Type
+	byType map[reflect.Type]string
+}{
+	byName: make(map[string]reflect.Type),
+	byType: make(map[reflect.Type]string),
+}
+
+func init() {
+	for _, v := range []any{
+		false, "",
+		int(0), int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint8(0), uint16(0), uint32(0), uint64(0),
+		float32(0), float64(0), complex64(0), complex128(0),
+		[]byte(nil), []any(nil), map[string]any(nil),
+		time.Time{}, time.Duration(0),
+	} {
+		RegisterType(reflect.TypeOf(v).String(), v)
+	}
+}
+
+// RegisterType binds a concrete type to a stable name so that values of it
+// can be sent in interface typed parameters, results and fields. Both sides
+// must register the type under the same name. The type of v is registered
+// as is, register a pointer to register the pointer type.
+func RegisterType(name string, v any) {
+	t := reflect.TypeOf(v)
+	if name == "" || t == nil {
+		panic("transmission: register type with empty name or nil value")
+	}
+	types.Lock()
+	defer types.Unlock()
+	if exist, ok := types.byName[name]; ok && exist != t {
+		panic(fmt.Sprintf("transmission: type name %q registered for both %s and %s", name, exist, t))
+	}
+	if exist, ok := types.byType[t]; ok && exist != name {
+		panic(fmt.Sprintf("transmission: type %s registered as both %q and %q", t, exist, name))
+	}
+	types.byName[name] = t
+	types.byType[t] = name
+}
+
+// RegisteredType returns the type registered under name.
+func RegisteredType(name string) (reflect.Type, bool) {
+	types.RLock()
+	defer types.RUnlock()
+	t, ok := types.byName[name]
+	return t, ok
+}
+
+func registeredName(t reflect.Type) (string, bool) {
+	types.RLock()
+	defer types.RUnlock()
+	name, ok := types.byType[t]
+	return name, ok
+}
+
+// concreteType resolves a registered type name for a value of the interface
+// type in.
+func concreteType(name string, in reflect.Type) (reflect.Type, error) {
+	t, ok := RegisteredType(name)
+	if !ok {
+		return nil, fmt.Errorf(": type %q is not registered", name)
+	}
+	if !t.AssignableTo(in) {
+		return nil, fmt.Errorf(": type %q does not implement %s", name, in)
+	}
+	return t, nil
+}
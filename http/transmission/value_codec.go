@@ -0,0 +1,79 @@
+package transmission
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ValueEncoder returns the wire form of v. The wire form is built from nil,
+// bool, string, numbers, []any and map[string]any.
+type ValueEncoder func(v reflect.Value) (any, error)
+
+// ValueDecoder builds a value of type t from its wire form. Numbers arrive
+// as json.Number.
+type ValueDecoder func(val any, t reflect.Type) (reflect.Value, error)
+
+type valueCodec struct {
+	encode ValueEncoder
+	decode ValueDecoder
+}
+
+var valueCodecs sync.Map // map[reflect.Type]*valueCodec
+
+// RegisterValueCodecFor replaces the wire form of the concrete type t. The
+// codec applies wherever t appears in parameters and results, at any depth,
+// and takes precedence over json.Marshaler and json.Unmarshaler.
+func RegisterValueCodecFor(t reflect.Type, encode ValueEncoder, decode ValueDecoder) {
+	if t == nil || t.Kind() == reflect.Interface {
+		panic("transmission: value codec requires a concrete type")
+	}
+	if encode == nil || decode == nil {
+		panic("transmission: value codec for " + t.String() + " requires both encoder and decoder")
+	}
+	valueCodecs.Store(t, &valueCodec{encode: encode, decode: decode})
+}
+
+// RegisterValueCodec is the typed form of RegisterValueCodecFor.
+func RegisterValueCodec[T any](encode func(T) (any, error), decode func(any) (T, error)) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	RegisterValueCodecFor(t,
+		func(v reflect.Value) (any, error) {
+			return encode(v.Interface().(T))
+		},
+		func(val any, _ reflect.Type) (reflect.Value, error) {
+			v, err := decode(val)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(&v).Elem(), nil
+		},
+	)
+}
+
+func lookupValueCodec(t reflect.Type) (*valueCodec, bool) {
+	c, ok := valueCodecs.Load(t)
+	if !ok {
+		return nil, false
+	}
+	return c.(*valueCodec), true
+}
+
+func (c *valueCodec) encodeValue(v reflect.Value) (any, error) {
+	val, err := c.encode(v)
+	if err != nil {
+		return nil, fmt.Errorf(": encode %s: %v", v.Type(), err)
+	}
+	return val, nil
+}
+
+func (c *valueCodec) decodeValue(val any, t reflect.Type) (reflect.Value, error) {
+	v, err := c.decode(val, t)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf(": decode %s: %v", t, err)
+	}
+	if !v.IsValid() || v.Type() != t {
+		return reflect.Value{}, fmt.Errorf(": decode %s: codec returned a value of another type", t)
+	}
+	return v, nil
+}
@@ -0,0 +1,144 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Rule checks a field against the parameter written after "=" in the tag
+// and returns why it does not comply.
+type Rule func(v reflect.Value, param string) error
+
+var rules = struct {
+	sync.RWMutex
+	byName map[string]Rule
+}{
+	byName: map[string]Rule{
+		"required": required,
+		"min":      bound("at least", func(n, limit float64) bool { return n >= limit }),
+		"max":      bound("at most", func(n, limit float64) bool { return n <= limit }),
+		"len":      bound("exactly", func(n, limit float64) bool { return n == limit }),
+		"email":    email,
+		"oneof":    oneof,
+	},
+}
+
+// RegisterRule adds a named rule or replaces a built-in one: required, min,
+// max, len, email and oneof.
+func RegisterRule(name string, rule Rule) {
+	if name == "" || name == "omitempty" || rule == nil {
+		panic("validation: invalid rule " + name)
+	}
+	rules.Lock()
+	defer rules.Unlock()
+	rules.byName[name] = rule
+}
+
+func lookupRule(name string) (Rule, bool) {
+	rules.RLock()
+	defer rules.RUnlock()
+	r, ok := rules.byName[name]
+	return r, ok
+}
+
+// required rejects zero values, nil pointers and empty strings, slices and
+// maps.
+func required(v reflect.Value, _ string) error {
+	if isEmpty(v) {
+		return fmt.Errorf("is required")
+	}
+	return nil
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	}
+	return !v.IsValid() || v.IsZero()
+}
+
+// bound compares numbers by value and strings, slices, arrays and maps by
+// length. Nil pointers are left to required.
+func bound(relation string, ok func(n, limit float64) bool) Rule {
+	return func(v reflect.Value, param string) error {
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return fmt.Errorf("invalid limit %q", param)
+		}
+		v = indirect(v)
+		var (
+			n    float64
+			unit string
+		)
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = float64(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			n = float64(v.Uint())
+		case reflect.Float32, reflect.Float64:
+			n = v.Float()
+		case reflect.String:
+			n, unit = float64(utf8.RuneCountInString(v.String())), " characters"
+		case reflect.Slice, reflect.Array, reflect.Map:
+			n, unit = float64(v.Len()), " items"
+		case reflect.Invalid:
+			return nil
+		default:
+			return fmt.Errorf("cannot be bounded, kind %s", v.Kind())
+		}
+		if !ok(n, limit) {
+			return fmt.Errorf("must be %s %s%s", relation, param, unit)
+		}
+		return nil
+	}
+}
+
+func email(v reflect.Value, _ string) error {
+	v = indirect(v)
+	if v.Kind() != reflect.String {
+		return fmt.Errorf("cannot be an email, kind %s", v.Kind())
+	}
+	addr, err := mail.ParseAddress(v.String())
+	if err != nil || addr.Address != v.String() {
+		return fmt.Errorf("must be an email address")
+	}
+	return nil
+}
+
+// oneof accepts the space separated values of param.
+func oneof(v reflect.Value, param string) error {
+	v = indirect(v)
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	s := fmt.Sprint(v.Interface())
+	for _, allowed := range strings.Fields(param) {
+		if s == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of [%s]", param)
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func sortKeys(keys []reflect.Value) {
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+}
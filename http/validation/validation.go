@@ -0,0 +1,191 @@
+package validation
+
+import (
+	"fmt"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Tag is the struct tag holding the comma separated rules of a field, such
+// as `validate:"required,min=1,max=100"`. omitempty skips the remaining
+// rules of a zero field, "-" skips the field.
+const Tag = "validate"
+
+// Violation describes why a field failed a rule.
+type Violation struct {
+	Field string
+	Rule  string
+	Msg   string
+	Value any
+}
+
+// Param validates the decoded argument at order and reports the first
+// violation as a *dto.ValidateError.
+func Param(order int, v reflect.Value) error {
+	violation := Value(v)
+	if violation == nil {
+		return nil
+	}
+	return &dto.ValidateError{
+		Msg:               violation.Msg,
+		RequiredParamKind: v.Kind().String(),
+		RequestParamKind:  v.Kind().String(),
+		ParamOrder:        order,
+		Field:             violation.Field,
+		Rule:              violation.Rule,
+		Value:             violation.Value,
+	}
+}
+
+// Value applies the rules of the struct fields reachable from v, in field
+// order, and returns the first violation. Field paths use the json names,
+// e.g. "items[0].name" or "labels[env]".
+func Value(v reflect.Value) *Violation {
+	return walk(v, "")
+}
+
+func walk(v reflect.Value, path string) *Violation {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, f := range cachedFields(v.Type()) {
+			fv := v.Field(f.index)
+			fieldPath := f.name
+			if path != "" && f.name != "" {
+				fieldPath = path + "." + f.name
+			} else if f.name == "" {
+				fieldPath = path
+			}
+			if violation := check(fv, fieldPath, f.rules); violation != nil {
+				return violation
+			}
+			if violation := walk(fv, fieldPath); violation != nil {
+				return violation
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if !containsStruct(v.Type().Elem()) {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if violation := walk(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); violation != nil {
+				return violation
+			}
+		}
+	case reflect.Map:
+		if !v.CanInterface() || !containsStruct(v.Type().Elem()) {
+			return nil
+		}
+		keys := v.MapKeys()
+		sortKeys(keys)
+		for _, k := range keys {
+			if violation := walk(v.MapIndex(k), fmt.Sprintf("%s[%v]", path, k.Interface())); violation != nil {
+				return violation
+			}
+		}
+	}
+	return nil
+}
+
+func check(v reflect.Value, path string, rules []rule) *Violation {
+	for _, r := range rules {
+		if r.name == "omitempty" {
+			if isEmpty(v) {
+				return nil
+			}
+			continue
+		}
+		fn, ok := lookupRule(r.name)
+		if !ok {
+			return &Violation{Field: path, Rule: r.name, Msg: "unknown validation rule " + r.name}
+		}
+		if err := fn(v, r.param); err != nil {
+			return &Violation{Field: path, Rule: r.name, Msg: path + ": " + err.Error(), Value: valueOf(v)}
+		}
+	}
+	return nil
+}
+
+func valueOf(v reflect.Value) any {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// containsStruct reports whether values of t may hold tagged fields.
+func containsStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct || t.Kind() == reflect.Interface
+}
+
+type rule struct {
+	name  string
+	param string
+}
+
+type field struct {
+	// name is empty for embedded structs, their fields are promoted.
+	name  string
+	index int
+	rules []rule
+}
+
+var fieldCache sync.Map // map[reflect.Type][]field
+
+func cachedFields(t reflect.Type) []field {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.([]field)
+	}
+	f, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return f.([]field)
+}
+
+func typeFields(t reflect.Type) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get(Tag)
+		if tag == "-" || (!sf.IsExported() && !sf.Anonymous) {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+			if sf.Anonymous {
+				name = ""
+			}
+		}
+		fields = append(fields, field{name: name, index: i, rules: parseRules(tag)})
+	}
+	return fields
+}
+
+func parseRules(tag string) []rule {
+	if tag == "" {
+		return nil
+	}
+	var rules []rule
+	for _, item := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(item), "=")
+		if name != "" {
+			rules = append(rules, rule{name: name, param: param})
+		}
+	}
+	return rules
+}
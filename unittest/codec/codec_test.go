@@ -0,0 +1,151 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/go-kid/remote-ioc/http/codec"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+type Value struct {
+	Int    int64             `json:"int"`
+	Uint   uint64            `json:"uint"`
+	Float  float64           `json:"float"`
+	String string            `json:"string"`
+	Bytes  []byte            `json:"bytes"`
+	Bool   bool              `json:"bool"`
+	Ptr    *Value            `json:"ptr"`
+	Slice  []int8            `json:"slice"`
+	Map    map[string]string `json:"map"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := &Value{
+		Int:    math.MinInt64,
+		Uint:   math.MaxUint64,
+		Float:  -1.5e-7,
+		String: "中文 \" \\ \n",
+		Bytes:  []byte{0, 1, 2, 255},
+		Bool:   true,
+		Ptr:    &Value{Int: -33, Uint: 1 << 40, Float: 1},
+		Slice:  []int8{-128, -1, 0, 127},
+		Map:    map[string]string{"a": "b", "": "empty"},
+	}
+	for _, name := range codec.Names() {
+		t.Run(name, func(t *testing.T) {
+			cc, ok := codec.Get(name)
+			assert.True(t, ok)
+			data, err := cc.Marshal(in)
+			assert.NoError(t, err)
+			var out = &Value{}
+			assert.NoError(t, cc.Unmarshal(data, out))
+			assert.Equal(t, in, out)
+		})
+	}
+}
+
+func TestPayloadNumbers(t *testing.T) {
+	in := &dto.Payload{Params: []*dto.Param{
+		{Order: 1, Kind: "int64", Value: int64(math.MaxInt64)},
+		{Order: 2, Kind: "uint64", Value: uint64(math.MaxUint64)},
+		{Order: 3, Kind: "float64", Value: 0.1},
+	}}
+	for _, name := range codec.Names() {
+		t.Run(name, func(t *testing.T) {
+			cc, _ := codec.Get(name)
+			data, err := cc.Marshal(in)
+			assert.NoError(t, err)
+			var out = &dto.Payload{}
+			assert.NoError(t, cc.Unmarshal(data, out))
+			assert.Equal(t, json.Number("9223372036854775807"), out.Params[0].Value)
+			assert.Equal(t, json.Number("18446744073709551615"), out.Params[1].Value)
+			assert.Equal(t, json.Number("0.1"), out.Params[2].Value)
+		})
+	}
+}
+
+func TestBigIntegers(t *testing.T) {
+	for _, name := range []string{codec.MessagePack, codec.CBOR} {
+		t.Run(name, func(t *testing.T) {
+			cc, _ := codec.Get(name)
+			for _, n := range []json.Number{"18446744073709551616", "-9223372036854775809"} {
+				_, err := cc.Marshal(map[string]any{"n": n})
+				assert.ErrorContains(t, err, "overflows 64 bits")
+			}
+		})
+	}
+	t.Run("CBORBignum", func(t *testing.T) {
+		cc, _ := codec.Get(codec.CBOR)
+		var out any
+		// tag 2 over h'0100', 256
+		assert.NoError(t, cc.Unmarshal([]byte{0xc2, 0x42, 0x01, 0x00}, &out))
+		assert.Equal(t, json.Number("256"), out)
+		// tag 2 over h'010000000000000000', 2^64
+		err := cc.Unmarshal([]byte{0xc2, 0x49, 0x01, 0, 0, 0, 0, 0, 0, 0, 0}, &out)
+		assert.ErrorContains(t, err, "overflows 64 bits")
+		// -2^64 as a plain negative integer
+		err = cc.Unmarshal([]byte{0x3b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, &out)
+		assert.ErrorContains(t, err, "overflows 64 bits")
+	})
+}
+
+func TestCBORIndefiniteLength(t *testing.T) {
+	cc, _ := codec.Get(codec.CBOR)
+	// {_ "a": [_ 1, -2], "b": (_ "x", "y"), "c": 1.5 as half float}
+	data := []byte{
+		0xbf,
+		0x61, 'a', 0x9f, 0x01, 0x21, 0xff,
+		0x61, 'b', 0x7f, 0x61, 'x', 0x61, 'y', 0xff,
+		0x61, 'c', 0xf9, 0x3e, 0x00,
+		0xff,
+	}
+	var out any
+	assert.NoError(t, cc.Unmarshal(data, &out))
+	assert.Equal(t, map[string]any{
+		"a": []any{json.Number("1"), json.Number("-2")},
+		"b": "xy",
+		"c": json.Number("1.5"),
+	}, out)
+}
+
+func TestMaxDepth(t *testing.T) {
+	nested := func(prefix byte, depth int, leaf byte) []byte {
+		return append(bytes.Repeat([]byte{prefix}, depth), leaf)
+	}
+	for name, tc := range map[string]struct {
+		codec  string
+		prefix byte
+	}{
+		// one element arrays
+		"MessagePack": {codec.MessagePack, 0x91},
+		// tag 6, dropped on decoding
+		"CBOR": {codec.CBOR, 0xc6},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			cc, _ := codec.Get(tc.codec)
+			var out any
+			assert.NoError(t, cc.Unmarshal(nested(tc.prefix, 100, 0x01), &out))
+			err := cc.Unmarshal(nested(tc.prefix, 1<<20, 0x01), &out)
+			assert.ErrorContains(t, err, "exceeded max depth")
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	cc, ok := codec.Negotiate(codec.DefaultPreference(), []string{codec.JSON, codec.CBOR})
+	assert.True(t, ok)
+	assert.Equal(t, codec.CBOR, cc.Name())
+	_, ok = codec.Negotiate([]string{codec.MessagePack}, []string{codec.JSON})
+	assert.False(t, ok)
+
+	cc, ok = codec.ForContentType("application/msgpack; charset=binary")
+	assert.True(t, ok)
+	assert.Equal(t, codec.MessagePack, cc.Name())
+	cc, ok = codec.ForContentType("")
+	assert.True(t, ok)
+	assert.Equal(t, codec.JSON, cc.Name())
+}
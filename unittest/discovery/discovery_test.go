@@ -0,0 +1,112 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"github.com/go-kid/remote-ioc/http/discovery"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStatic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	instance := &discovery.Instance{Addr: "http://a:80", RoutePrefix: "/api"}
+	ch, err := discovery.Static(instance).Watch(ctx, "Any")
+	assert.NoError(t, err)
+	assert.Equal(t, []*discovery.Instance{instance}, <-ch)
+	assert.Equal(t, "http://a:80/api", instance.URL())
+	cancel()
+	_, ok := <-ch
+	assert.False(t, ok)
+}
+
+func TestFunc(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		instances = []*discovery.Instance{{Addr: "http://b"}, {Addr: "http://a"}}
+		fail      bool
+	)
+	lookup := func(_ context.Context, serviceId string) ([]*discovery.Instance, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if fail {
+			return nil, errors.New("unavailable")
+		}
+		return instances, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := discovery.Func(lookup, 10*time.Millisecond).Watch(ctx, "Any")
+	assert.NoError(t, err)
+	assert.Equal(t, []*discovery.Instance{{Addr: "http://a"}, {Addr: "http://b"}}, <-ch)
+
+	mu.Lock()
+	fail = true
+	mu.Unlock()
+	select {
+	case set := <-ch:
+		t.Fatalf("unexpected set %v", set)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	mu.Lock()
+	fail = false
+	instances = []*discovery.Instance{{Addr: "http://c", Zone: "z1"}}
+	mu.Unlock()
+	select {
+	case set := <-ch:
+		assert.Equal(t, []*discovery.Instance{{Addr: "http://c", Zone: "z1"}}, set)
+	case <-time.After(time.Second):
+		t.Fatal("no update")
+	}
+
+	_, err = discovery.Func(func(context.Context, string) ([]*discovery.Instance, error) {
+		return nil, errors.New("unavailable")
+	}, 0).Watch(ctx, "Any")
+	assert.Error(t, err)
+}
+
+type stubResolver struct {
+	records map[string][]*net.SRV
+}
+
+func (r *stubResolver) LookupSRV(_ context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	cname := "_" + service + "._" + proto + "." + name
+	records, ok := r.records[cname]
+	if !ok {
+		return "", nil, &net.DNSError{Err: "no such host", Name: cname, IsNotFound: true}
+	}
+	return cname, records, nil
+}
+
+func TestDNS(t *testing.T) {
+	resolver := &stubResolver{records: map[string][]*net.SRV{
+		"_orders._tcp.svc.local": {
+			{Target: "a.eu.svc.local.", Port: 8080, Priority: 10, Weight: 3},
+			{Target: "b.us.svc.local.", Port: 8081, Priority: 10, Weight: 1},
+			{Target: "c.eu.svc.local.", Port: 8082, Priority: 20, Weight: 5},
+		},
+	}}
+	d := discovery.DNS(discovery.DNSConfig{
+		Domain:   "svc.local",
+		Resolver: resolver,
+		Zone: func(target string) string {
+			return strings.Split(target, ".")[1]
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := d.Watch(ctx, "Orders")
+	assert.NoError(t, err)
+	assert.Equal(t, []*discovery.Instance{
+		{Addr: "http://a.eu.svc.local:8080", Zone: "eu", Weight: 3, Metadata: map[string]string{"priority": "10"}},
+		{Addr: "http://b.us.svc.local:8081", Zone: "us", Weight: 1, Metadata: map[string]string{"priority": "10"}},
+	}, <-ch)
+
+	_, err = d.Watch(ctx, "Missing")
+	assert.Error(t, err)
+}
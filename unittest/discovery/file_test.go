@@ -0,0 +1,88 @@
+package discovery
+
+import (
+	"context"
+	"github.com/go-kid/remote-ioc/http/discovery"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	want := map[string][]*discovery.Instance{
+		"Orders": {{Addr: "http://a:80", Zone: "eu", Weight: 2, Metadata: map[string]string{"rack": "r1"}}},
+		"Empty":  {},
+	}
+	services, err := discovery.ReadFile(write("services.yaml", `
+Orders:
+  - addr: http://a:80
+    zone: eu
+    weight: 2
+    metadata:
+      rack: r1
+Empty: []
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, want, services)
+	services, err = discovery.ReadFile(write("services.json",
+		`{"Orders": [{"addr": "http://a:80", "zone": "eu", "weight": 2, "metadata": {"rack": "r1"}}], "Empty": []}`))
+	assert.NoError(t, err)
+	assert.Equal(t, want, services)
+
+	for name, content := range map[string]string{
+		"empty":       "",
+		"unknown key": "Orders:\n  - addr: http://a\n    weigth: 2\n",
+		"no scheme":   "Orders:\n  - addr: a:80\n",
+		"syntax":      "Orders: [",
+		"list":        "- addr: http://a\n",
+	} {
+		_, err = discovery.ReadFile(write("bad.yaml", content))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "services.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("Orders:\n  - addr: http://a\n"), 0644))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := discovery.File(path).Watch(ctx, "Orders")
+	assert.NoError(t, err)
+	assert.Equal(t, []*discovery.Instance{{Addr: "http://a"}}, <-ch)
+
+	next := func() []*discovery.Instance {
+		select {
+		case instances := <-ch:
+			return instances
+		case <-time.After(time.Second):
+			t.Fatal("no update")
+			return nil
+		}
+	}
+	assert.NoError(t, os.WriteFile(path, []byte("Orders:\n  - addr: http://b\n  - addr: http://a\n"), 0644))
+	assert.Equal(t, []*discovery.Instance{{Addr: "http://a"}, {Addr: "http://b"}}, next())
+
+	assert.NoError(t, os.WriteFile(path, []byte("Orders:\n  - addr: b\n"), 0644))
+	select {
+	case instances := <-ch:
+		t.Fatalf("malformed file applied: %v", instances)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// replaced through a rename, as editors and config maps do
+	tmp := path + ".tmp"
+	assert.NoError(t, os.WriteFile(tmp, []byte("Orders:\n  - addr: http://c\nBilling:\n  - addr: http://d\n"), 0644))
+	assert.NoError(t, os.Rename(tmp, path))
+	assert.Equal(t, []*discovery.Instance{{Addr: "http://c"}}, next())
+
+	_, err = discovery.File(filepath.Join(t.TempDir(), "missing.yaml")).Watch(ctx, "Orders")
+	assert.Error(t, err)
+}
@@ -0,0 +1,176 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/ioc/configure/loader"
+	"github.com/go-kid/ioc/registry"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/go-resty/resty/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAdmin(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&WaiterImpl{interrupted: make(chan error, 8)}),
+		server.Handle(server.Config{
+			Addr:         ":8933",
+			Authenticate: server.BearerToken("app"),
+			Admin: &server.AdminConfig{
+				Authenticate: server.BasicAuth("ops", "secret"),
+				Addr:         "127.0.0.1:8934",
+			},
+		}),
+	)
+	var c = &WaiterInvoker{}
+	ioc.RunTest(t,
+		app.SetComponents(c),
+		client.Remote(client.Config{
+			Servers: []client.ServerConfig{{Addr: "http://localhost:8933"}},
+			Headers: map[string]string{"Authorization": "Bearer app"},
+		}),
+	)
+	const admin = "http://127.0.0.1:8934" + constant.RouteAdmin
+	ops := func() *resty.Request {
+		return resty.New().R().SetBasicAuth("ops", "secret")
+	}
+	wait := func() error {
+		_, err := c.Invoke("Wait", context.Background(), time.Duration(0))
+		return err
+	}
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		response, err := resty.New().R().SetAuthToken("app").Get(admin + constant.RouteAdminRoutes)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode())
+		// the admin routes have their own listener
+		response, err = ops().Get("http://localhost:8933" + constant.RouteAdmin + constant.RouteAdminRoutes)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode())
+	})
+	t.Run("Routes", func(t *testing.T) {
+		var routes []*dto.Route
+		_, err := ops().SetResult(&routes).Get(admin + constant.RouteAdminRoutes)
+		assert.NoError(t, err)
+		assert.Contains(t, routes, &dto.Route{Method: http.MethodPost, Path: "/component/Waiter/methods/Wait"})
+		assert.Contains(t, routes, &dto.Route{Method: http.MethodGet, Path: constant.RouteHealth})
+		assert.Contains(t, routes, &dto.Route{Method: http.MethodPut, Path: constant.RouteAdmin + constant.RouteAdminMaintenance})
+	})
+	t.Run("Calls", func(t *testing.T) {
+		done := make(chan error, 1)
+		go func() {
+			_, err := c.Invoke("Wait", context.Background(), 300*time.Millisecond)
+			done <- err
+		}()
+		time.Sleep(100 * time.Millisecond)
+		var calls []*dto.CallState
+		_, err := ops().SetResult(&calls).Get(admin + constant.RouteAdminCalls)
+		assert.NoError(t, err)
+		if assert.Len(t, calls, 1) {
+			assert.Equal(t, "Waiter", calls[0].ServiceId)
+			assert.Equal(t, "Wait", calls[0].Method)
+			assert.NotEmpty(t, calls[0].Id)
+			assert.NotEmpty(t, calls[0].Caller)
+			assert.GreaterOrEqual(t, calls[0].DurationMs, int64(50))
+		}
+		assert.NoError(t, <-done)
+	})
+	t.Run("Methods", func(t *testing.T) {
+		toggle := func(method string, enabled bool) *resty.Response {
+			response, err := ops().SetBody(&dto.MethodState{ServiceKey: dto.ServiceKey{ServiceId: "Waiter"}, Method: method, Enabled: enabled}).
+				Put(admin + constant.RouteAdminMethods)
+			assert.NoError(t, err)
+			return response
+		}
+		assert.Equal(t, http.StatusOK, toggle("Wait", false).StatusCode())
+		var states []*dto.MethodState
+		_, err := ops().SetResult(&states).Get(admin + constant.RouteAdminMethods)
+		assert.NoError(t, err)
+		assert.Contains(t, states, &dto.MethodState{ServiceKey: dto.ServiceKey{ServiceId: "Waiter"}, Method: "Wait", Enabled: false})
+		err = wait()
+		assert.True(t, errors.Is(err, client.ErrUnavailable), "%v", err)
+		assert.ErrorContains(t, err, "remote method Waiter.Wait is disabled")
+
+		assert.Equal(t, http.StatusOK, toggle("Wait", true).StatusCode())
+		assert.NoError(t, wait())
+		assert.Equal(t, http.StatusNotFound, toggle("Sleep", false).StatusCode())
+	})
+	t.Run("Maintenance", func(t *testing.T) {
+		maintenance := func(m *dto.Maintenance) {
+			response, err := ops().SetBody(m).Put(admin + constant.RouteAdminMaintenance)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, response.StatusCode())
+		}
+		maintenance(&dto.Maintenance{Enabled: true, Message: "upgrading"})
+		err := wait()
+		assert.True(t, errors.Is(err, client.ErrUnavailable), "%v", err)
+		assert.ErrorContains(t, err, "in maintenance: upgrading")
+		response, err := resty.New().R().Get("http://localhost:8933" + constant.RouteHealth)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode())
+
+		maintenance(&dto.Maintenance{})
+		assert.NoError(t, wait())
+		response, err = resty.New().R().Get("http://localhost:8933" + constant.RouteHealth)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode())
+	})
+	t.Run("Metrics", func(t *testing.T) {
+		var metrics []*dto.MethodMetrics
+		_, err := ops().SetResult(&metrics).Get(admin + constant.RouteAdminMetrics)
+		assert.NoError(t, err)
+		waiter, ok := lo.Find(metrics, func(m *dto.MethodMetrics) bool { return m.Method == "Wait" })
+		assert.True(t, ok)
+		assert.Equal(t, int64(3), waiter.Calls)
+	})
+}
+
+func TestAdminProperties(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetConfigLoader(loader.NewRawLoader()),
+		app.SetConfig(`
+remote-ioc:
+  server:
+    addr: :8935
+    admin:
+      auth:
+        token: ops
+`),
+		app.SetComponents(&StagingAccountingImpl{Server: "a"}),
+		server.Handle(server.Config{}),
+	)
+	response, err := resty.New().R().SetAuthToken("ops").Get("http://localhost:8935" + constant.RouteAdmin + constant.RouteAdminMethods)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode())
+	response, err = resty.New().R().Get("http://localhost:8935" + constant.RouteAdmin + constant.RouteAdminMethods)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode())
+}
+
+func TestAdminListenerNotLeaked(t *testing.T) {
+	taken, err := net.Listen("tcp", ":8941")
+	assert.NoError(t, err)
+	defer taken.Close()
+	_, err = ioc.Run(
+		app.SetRegistry(registry.NewRegistry()),
+		app.SetComponents(&StagingAccountingImpl{}),
+		server.Handle(server.Config{
+			Addr:  ":8941",
+			Admin: &server.AdminConfig{Authenticate: server.BasicAuth("ops", "secret"), Addr: ":8942"},
+		}),
+	)
+	assert.ErrorContains(t, err, "address already in use")
+	admin, err := net.Listen("tcp", ":8942")
+	assert.NoError(t, err)
+	_ = admin.Close()
+}
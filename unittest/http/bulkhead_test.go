@@ -0,0 +1,121 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBulkheads(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&WaiterImpl{interrupted: make(chan error, 8)}),
+		server.Handle(server.Config{
+			Addr: ":8930",
+			Bulkheads: server.Bulkheads{
+				Methods: map[string]server.Bulkhead{
+					"Waiter.Wait": {MaxConcurrent: 1, MaxQueue: 1, QueueTimeout: 150 * time.Millisecond},
+				},
+			},
+		}),
+	)
+	newInvoker := func(retry client.RetryPolicy) *WaiterInvoker {
+		var c = &WaiterInvoker{}
+		ioc.RunTest(t,
+			app.SetComponents(c),
+			client.Remote(client.Config{
+				Servers: []client.ServerConfig{{Addr: "http://localhost:8930"}},
+				Retry:   retry,
+			}),
+		)
+		return c
+	}
+
+	t.Run("Saturated", func(t *testing.T) {
+		c := newInvoker(client.RetryPolicy{})
+		var (
+			wg   sync.WaitGroup
+			errs = make([]error, 2)
+		)
+		// the first call runs for 300ms, the second waits in the queue
+		for i, d := range []time.Duration{300 * time.Millisecond, 0} {
+			i, d := i, d
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, errs[i] = c.Invoke("Wait", context.Background(), d)
+			}()
+			time.Sleep(50 * time.Millisecond)
+		}
+		_, err := c.Invoke("Wait", context.Background(), time.Duration(0))
+		assert.True(t, errors.Is(err, client.ErrOverloaded), "%v", err)
+		var invokeErr *client.InvokeError
+		assert.True(t, errors.As(err, &invokeErr))
+		assert.Equal(t, http.StatusServiceUnavailable, invokeErr.StatusCode)
+		assert.ErrorContains(t, err, "queue is full")
+
+		wg.Wait()
+		assert.NoError(t, errs[0])
+		assert.True(t, errors.Is(errs[1], client.ErrOverloaded), "%v", errs[1])
+		assert.ErrorContains(t, errs[1], "no slot freed in 150ms")
+	})
+	t.Run("Admin", func(t *testing.T) {
+		var states []*dto.BulkheadState
+		_, err := resty.New().R().SetResult(&states).Get("http://localhost:8930" + constant.RouteBulkheads)
+		assert.NoError(t, err)
+		assert.Len(t, states, 1)
+		assert.Equal(t, "Waiter", states[0].ServiceId)
+		assert.Equal(t, "Wait", states[0].Method)
+		assert.Equal(t, 1, states[0].MaxConcurrent)
+		assert.Equal(t, 0, states[0].Active)
+		assert.Equal(t, int64(1), states[0].Peak)
+		assert.Equal(t, int64(1), states[0].Admitted)
+		assert.Equal(t, int64(1), states[0].Rejected)
+		assert.Equal(t, int64(1), states[0].TimedOut)
+	})
+	t.Run("Retry", func(t *testing.T) {
+		c := newInvoker(client.RetryPolicy{Attempts: 2})
+		done := make(chan error, 1)
+		go func() {
+			_, err := c.Invoke("Wait", context.Background(), 250*time.Millisecond)
+			done <- err
+		}()
+		time.Sleep(50 * time.Millisecond)
+		// times out in the queue, then finds the slot freed on the retry
+		result, err := c.Invoke("Wait", context.Background(), time.Duration(0))
+		assert.NoError(t, err)
+		assert.Equal(t, true, result[0])
+		assert.NoError(t, <-done)
+	})
+}
+
+func TestBulkheadsPerNamespace(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&StagingAccountingImpl{}, &ProdAccountingImpl{}),
+		server.Handle(server.Config{
+			Addr: ":8937",
+			Bulkheads: server.Bulkheads{
+				Services: map[string]server.Bulkhead{"Accounting": {MaxConcurrent: 1}},
+			},
+		}),
+	)
+	var states []*dto.BulkheadState
+	_, err := resty.New().R().SetResult(&states).Get("http://localhost:8937" + constant.RouteBulkheads)
+	assert.NoError(t, err)
+	assert.Len(t, states, 2)
+	for i, namespace := range []string{"prod", "staging"} {
+		assert.Equal(t, namespace, states[i].Namespace)
+		assert.Equal(t, "Accounting", states[i].ServiceId)
+		assert.Equal(t, "", states[i].Method)
+	}
+}
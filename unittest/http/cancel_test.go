@@ -0,0 +1,189 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/codec"
+	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/go-kid/remote-ioc/http/transmission"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCancellation(t *testing.T) {
+	var impl = &WaiterImpl{interrupted: make(chan error, 1)}
+	ioc.RunTest(t,
+		app.SetComponents(impl),
+		server.Handle(server.Config{
+			Addr: ":8906",
+		}),
+	)
+	interrupted := func(t *testing.T) {
+		select {
+		case err := <-impl.interrupted:
+			assert.ErrorIs(t, err, context.Canceled)
+		case <-time.After(2 * time.Second):
+			t.Fatal("server method was not interrupted")
+		}
+	}
+	var c = &WaiterInvoker{}
+	ioc.RunTest(t,
+		app.SetComponents(c),
+		client.Remote(client.Config{
+			Servers: []client.ServerConfig{{Addr: "http://localhost:8906"}},
+		}),
+	)
+
+	t.Run("Deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := c.Invoke("Wait", ctx, time.Minute)
+		assert.ErrorIs(t, err, client.ErrTimeout)
+		assert.Less(t, time.Since(start), time.Second)
+		interrupted(t)
+	})
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+		_, err := c.Invoke("Wait", ctx, time.Minute)
+		assert.ErrorIs(t, err, client.ErrCanceled)
+		assert.ErrorIs(t, err, context.Canceled)
+		interrupted(t)
+	})
+	t.Run("Completed", func(t *testing.T) {
+		result, err := c.Invoke("Wait", context.Background(), time.Millisecond)
+		assert.NoError(t, err)
+		assert.Equal(t, true, result[0])
+	})
+	t.Run("ExplicitCancel", func(t *testing.T) {
+		cc, _ := codec.Get(codec.JSON)
+		ctxParam, err := transmission.EncryptParam(1, reflect.TypeOf((*context.Context)(nil)).Elem(), context.Background(), nil)
+		assert.NoError(t, err)
+		durationParam, err := transmission.EncryptParam(2, reflect.TypeOf(time.Duration(0)), time.Minute, nil)
+		assert.NoError(t, err)
+		data, err := cc.Marshal(&dto.Payload{Params: []*dto.Param{ctxParam, durationParam}})
+		assert.NoError(t, err)
+
+		var (
+			callId = "explicit-cancel"
+			addr   = "http://localhost:8906"
+			done   = make(chan *resty.Response, 1)
+		)
+		go func() {
+			response, _ := resty.New().R().
+				SetHeader("Content-Type", cc.ContentType()).
+				SetHeader(constant.HeaderCallId, callId).
+				SetBody(data).
+				Post(addr + fmt.Sprintf(constant.RouteMethod, "Waiter", "Wait"))
+			done <- response
+		}()
+		var response *resty.Response
+		assert.Eventually(t, func() bool {
+			response, err = resty.New().R().Delete(addr + fmt.Sprintf(constant.RouteCall, callId))
+			return err == nil && response.StatusCode() == http.StatusNoContent
+		}, 2*time.Second, 20*time.Millisecond)
+		interrupted(t)
+
+		select {
+		case response = <-done:
+			var payload = &dto.Payload{}
+			assert.NoError(t, cc.Unmarshal(response.Body(), payload))
+			assert.Equal(t, false, payload.Params[0].Value)
+		case <-time.After(2 * time.Second):
+			t.Fatal("cancelled call did not respond")
+		}
+
+		response, err = resty.New().R().Delete(addr + fmt.Sprintf(constant.RouteCall, callId))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode())
+	})
+}
+
+func TestCancellationCallers(t *testing.T) {
+	var impl = &WaiterImpl{interrupted: make(chan error, 1)}
+	ioc.RunTest(t,
+		app.SetComponents(impl),
+		server.Handle(server.Config{
+			Addr: ":8944",
+			RateLimits: server.RateLimits{CallerKey: func(r *http.Request) string {
+				return r.Header.Get("X-Caller")
+			}},
+		}),
+	)
+	var (
+		cc, _  = codec.Get(codec.JSON)
+		addr   = "http://localhost:8944"
+		route  = addr + fmt.Sprintf(constant.RouteMethod, "Waiter", "Wait")
+		callId = "shared-id"
+	)
+	wait := func(caller string, d time.Duration, withContext bool) *resty.Response {
+		var params []*dto.Param
+		if withContext {
+			ctxParam, err := transmission.EncryptParam(1, reflect.TypeOf((*context.Context)(nil)).Elem(), context.Background(), nil)
+			assert.NoError(t, err)
+			params = append(params, ctxParam)
+		}
+		durationParam, err := transmission.EncryptParam(2, reflect.TypeOf(time.Duration(0)), d, nil)
+		assert.NoError(t, err)
+		data, err := cc.Marshal(&dto.Payload{Params: append(params, durationParam)})
+		assert.NoError(t, err)
+		response, err := resty.New().R().
+			SetHeader("Content-Type", cc.ContentType()).
+			SetHeader(constant.HeaderCallId, callId).
+			SetHeader("X-Caller", caller).
+			SetBody(data).
+			Post(route)
+		assert.NoError(t, err)
+		return response
+	}
+	cancel := func(caller string) int {
+		response, err := resty.New().R().
+			SetHeader("X-Caller", caller).
+			Delete(addr + fmt.Sprintf(constant.RouteCall, callId))
+		assert.NoError(t, err)
+		return response.StatusCode()
+	}
+
+	t.Run("Scoped", func(t *testing.T) {
+		done := make(chan *resty.Response, 1)
+		go func() { done <- wait("alice", time.Minute, true) }()
+		assert.Eventually(t, func() bool {
+			return wait("alice", time.Millisecond, true).StatusCode() == http.StatusConflict
+		}, 2*time.Second, 20*time.Millisecond)
+
+		// the same id of another caller neither collides nor cancels
+		assert.Equal(t, http.StatusOK, wait("bob", time.Millisecond, true).StatusCode())
+		assert.Equal(t, http.StatusNotFound, cancel("bob"))
+		select {
+		case <-done:
+			t.Fatal("call of alice was cancelled by bob")
+		default:
+		}
+
+		assert.Equal(t, http.StatusNoContent, cancel("alice"))
+		select {
+		case err := <-impl.interrupted:
+			assert.ErrorIs(t, err, context.Canceled)
+		case <-time.After(2 * time.Second):
+			t.Fatal("server method was not interrupted")
+		}
+		<-done
+	})
+	t.Run("MissingContext", func(t *testing.T) {
+		response := wait("alice", time.Millisecond, false)
+		assert.Equal(t, http.StatusOK, response.StatusCode())
+		var payload = &dto.Payload{}
+		assert.NoError(t, cc.Unmarshal(response.Body(), payload))
+		assert.Equal(t, true, payload.Params[0].Value)
+	})
+}
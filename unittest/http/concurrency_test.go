@@ -0,0 +1,78 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimit(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&WaiterImpl{interrupted: make(chan error, 8)}),
+		server.Handle(server.Config{
+			Addr: ":8931",
+		}),
+	)
+	newInvoker := func(limit client.ConcurrencyLimit) (*WaiterInvoker, func() *client.ServerInfo) {
+		var (
+			c    = &WaiterInvoker{}
+			last atomic.Pointer[client.ServerInfo]
+		)
+		ioc.RunTest(t,
+			app.SetComponents(c),
+			client.Remote(client.Config{
+				Servers: []client.ServerConfig{{Addr: "http://localhost:8931"}},
+				LoadBalance: func(servers []*client.ServerInfo) int {
+					last.Store(servers[0])
+					return 0
+				},
+				Concurrency: limit,
+			}),
+		)
+		return c, last.Load
+	}
+
+	t.Run("Rejected", func(t *testing.T) {
+		c, _ := newInvoker(client.ConcurrencyLimit{Max: 2})
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := c.Invoke("Wait", context.Background(), 300*time.Millisecond)
+				assert.NoError(t, err)
+			}()
+		}
+		time.Sleep(50 * time.Millisecond)
+		start := time.Now()
+		_, err := c.Invoke("Wait", context.Background(), time.Duration(0))
+		assert.True(t, errors.Is(err, client.ErrLimited), "%v", err)
+		assert.Less(t, time.Since(start), 50*time.Millisecond)
+		wg.Wait()
+		_, err = c.Invoke("Wait", context.Background(), time.Duration(0))
+		assert.NoError(t, err)
+	})
+	t.Run("Decrease", func(t *testing.T) {
+		c, last := newInvoker(client.ConcurrencyLimit{Max: 4, Latency: 100 * time.Millisecond, Backoff: 0.5})
+		_, err := c.Invoke("Wait", context.Background(), 200*time.Millisecond)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, last().ConcurrencyLimit("Waiter"))
+	})
+	t.Run("Increase", func(t *testing.T) {
+		c, last := newInvoker(client.ConcurrencyLimit{Initial: 1, Max: 4})
+		for i := 0; i < 3; i++ {
+			_, err := c.Invoke("Wait", context.Background(), time.Duration(0))
+			assert.NoError(t, err)
+		}
+		// calls one at a time only grow the limit while they use half of it
+		assert.Equal(t, 2, last().ConcurrencyLimit("Waiter"))
+	})
+}
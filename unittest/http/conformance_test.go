@@ -0,0 +1,102 @@
+package http
+
+import (
+	"errors"
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/codec"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestConformance(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&ConformanceImpl{}),
+		server.Handle(server.Config{
+			Addr: ":8898",
+		}),
+	)
+
+	one := 1
+	tagged := Tagged{
+		Name:      "outer",
+		Omit:      "",
+		Skip:      "skipped",
+		Dash:      "dash",
+		Quoted:    math.MaxInt64,
+		QuotedStr: "quoted \"text\"",
+		Untagged:  "untagged",
+		Embedded: Embedded{
+			Name:  "shadowed",
+			Inner: 7,
+		},
+		EmbeddedPtr: &EmbeddedPtr{Deep: "deep"},
+		Any:         map[string]any{"n": 1, "s": []any{"a", true, nil}},
+		Level:       1,
+		Ptr:         &one,
+		Err:         errors.New("boom"),
+		Bytes:       []byte("bytes"),
+	}
+	tagged.Anonymous.X = 3
+	received := tagged
+	received.Skip = ""
+	received.Embedded.Name = ""
+	received.Err = &dto.RemoteError{Message: "boom", Type: "*errors.errorString"}
+
+	var tests = []struct {
+		method string
+		in     any
+		want   any
+	}{
+		{"EchoInt8", int8(math.MinInt8), int8(math.MinInt8)},
+		{"EchoUint", uint(math.MaxUint), uint(math.MaxUint)},
+		{"EchoUint8", uint8(math.MaxUint8), uint8(math.MaxUint8)},
+		{"EchoFloat32", float32(0.1), float32(0.1)},
+		{"EchoComplex64", complex64(complex(1.5, -0.1)), complex64(complex(1.5, -0.1))},
+		{"EchoComplex128", complex(math.MaxFloat64, math.SmallestNonzeroFloat64), complex(math.MaxFloat64, math.SmallestNonzeroFloat64)},
+		{"EchoString", "中文 \x00", "中文 \x00"},
+		{"EchoBytes", []byte{0, 1, 254, 255}, []byte{0, 1, 254, 255}},
+		{"EchoBytes", []byte(nil), []byte(nil)},
+		{"EchoBytes", []byte{}, []byte{}},
+		{"EchoByteArray", [4]byte{1, 2, 3, 4}, [4]byte{1, 2, 3, 4}},
+		{"EchoNested", [][]int{{1}, {}, nil}, [][]int{{1}, {}, nil}},
+		{"EchoStringMap", map[string]int{"a": 1, "": 0}, map[string]int{"a": 1, "": 0}},
+		{"EchoStringMap", map[string]int(nil), map[string]int(nil)},
+		{"EchoIntMap", map[int]string{-1: "a", math.MaxInt: "b"}, map[int]string{-1: "a", math.MaxInt: "b"}},
+		{"EchoStructMap", map[string]*Sub{"a": {Float: 1.5}, "b": nil}, map[string]*Sub{"a": {Float: 1.5}, "b": nil}},
+		{"EchoLevelMap", map[Level]Level{0: 1, 1: 0}, map[Level]Level{0: 1, 1: 0}},
+		{"EchoIP", net.ParseIP("192.168.0.1"), net.ParseIP("192.168.0.1")},
+		{"EchoDuration", time.Duration(math.MaxInt64), time.Duration(math.MaxInt64)},
+		{"EchoTime", time.Date(2023, 1, 2, 3, 4, 5, 6, time.UTC), time.Date(2023, 1, 2, 3, 4, 5, 6, time.UTC)},
+		{"EchoTagged", tagged, received},
+		{"EchoTaggedPtr", &tagged, &received},
+		{"EchoTaggedPtr", (*Tagged)(nil), (*Tagged)(nil)},
+		{"EchoTaggedSlice", []Tagged{tagged, {}}, []Tagged{received, {}}},
+	}
+
+	for _, name := range codec.Names() {
+		t.Run(name, func(t *testing.T) {
+			var c = &ConformanceInvoker{}
+			ioc.RunTest(t,
+				app.SetComponents(c),
+				client.Remote(client.Config{
+					Servers: []client.ServerConfig{{Addr: "http://localhost:8898"}},
+					Codecs:  []string{name},
+				}),
+			)
+			for _, tt := range tests {
+				t.Run(tt.method, func(t *testing.T) {
+					results, err := c.Invoke(tt.method, tt.in)
+					assert.NoError(t, err)
+					assert.Equal(t, tt.want, results[0])
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"context"
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/codec"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestContextPropagation(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&ContextEchoImpl{}),
+		server.Handle(server.Config{
+			Addr: ":8905",
+		}),
+	)
+	for _, name := range codec.Names() {
+		t.Run(name, func(t *testing.T) {
+			var c = &ContextEchoInvoker{}
+			ioc.RunTest(t,
+				app.SetComponents(c),
+				client.Remote(client.Config{
+					Servers: []client.ServerConfig{{Addr: "http://localhost:8905"}},
+					Codecs:  []string{name},
+				}),
+			)
+			ctx := TenantKey.WithValue(context.Background(), &Tenant{Id: "t-1", Region: "eu"})
+			ctx = AttemptKey.WithValue(ctx, 3)
+			ctx = context.WithValue(ctx, TraceIdKey, "trace-42")
+			ctx = context.WithValue(ctx, unsentKey, "local only")
+
+			t.Run("Struct", func(t *testing.T) {
+				result, err := c.Invoke("Tenant", ctx)
+				assert.NoError(t, err)
+				assert.Equal(t, &Tenant{Id: "t-1", Region: "eu"}, result[0])
+			})
+			t.Run("Number", func(t *testing.T) {
+				result, err := c.Invoke("Attempt", ctx)
+				assert.NoError(t, err)
+				assert.Equal(t, []any{3, true}, result)
+			})
+			t.Run("ExistingKey", func(t *testing.T) {
+				result, err := c.Invoke("TraceId", ctx)
+				assert.NoError(t, err)
+				assert.Equal(t, "trace-42", result[0])
+			})
+			t.Run("Unregistered", func(t *testing.T) {
+				result, err := c.Invoke("Unsent", ctx)
+				assert.NoError(t, err)
+				assert.Equal(t, false, result[0])
+			})
+			t.Run("Absent", func(t *testing.T) {
+				result, err := c.Invoke("Attempt", context.Background())
+				assert.NoError(t, err)
+				assert.Equal(t, []any{0, false}, result)
+			})
+			t.Run("Nil", func(t *testing.T) {
+				result, err := c.Invoke("Tenant", nil)
+				assert.NoError(t, err)
+				assert.Nil(t, result[0])
+			})
+		})
+	}
+}
+
+func TestCtxToMapFilter(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&ContextEchoImpl{}),
+		server.Handle(server.Config{
+			Addr: ":8936",
+		}),
+	)
+	var c = &ContextEchoInvoker{}
+	ioc.RunTest(t,
+		app.SetComponents(c),
+		client.Remote(client.Config{
+			Servers:              []client.ServerConfig{{Addr: "http://localhost:8936"}},
+			SerializationFilters: []client.SerializationFilter{client.CtxToMapFilter},
+		}),
+	)
+	ctx := context.WithValue(context.Background(), TraceIdKey, "trace-42")
+	result, err := c.Invoke("TraceId", ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "trace-42", result[0])
+}
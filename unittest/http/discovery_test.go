@@ -0,0 +1,138 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/ioc/registry"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/discovery"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDiscovery(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&StagingAccountingImpl{Server: "a"}),
+		server.Handle(server.Config{Addr: ":8917"}),
+	)
+	ioc.RunTest(t,
+		app.SetComponents(&StagingAccountingImpl{Server: "b"}),
+		server.Handle(server.Config{Addr: ":8918"}),
+	)
+	var (
+		mu        sync.Mutex
+		instances = []*discovery.Instance{{Addr: "http://localhost:8917", Zone: "eu"}}
+	)
+	setInstances := func(set ...*discovery.Instance) {
+		mu.Lock()
+		defer mu.Unlock()
+		instances = set
+	}
+	lookup := func(_ context.Context, serviceId string) ([]*discovery.Instance, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, "Accounting", serviceId)
+		return instances, nil
+	}
+	var c = &StagingAccountingInvoker{}
+	ioc.RunTest(t,
+		app.SetComponents(c),
+		client.Remote(client.Config{
+			Discovery: discovery.Func(lookup, 20*time.Millisecond),
+		}),
+	)
+	balances := func() map[string]bool {
+		var seen = map[string]bool{}
+		for i := 0; i < 4; i++ {
+			result, err := c.Invoke("Balance")
+			if err != nil {
+				seen[err.Error()] = true
+				continue
+			}
+			seen[result[0].(string)] = true
+		}
+		return seen
+	}
+	assert.Equal(t, map[string]bool{"staging@a": true}, balances())
+
+	setInstances(
+		&discovery.Instance{Addr: "http://localhost:8917", Zone: "eu"},
+		&discovery.Instance{Addr: "http://localhost:8918", Zone: "us"},
+	)
+	assert.Eventually(t, func() bool {
+		return len(balances()) == 2
+	}, time.Second, 20*time.Millisecond)
+	assert.Equal(t, map[string]bool{"staging@a": true, "staging@b": true}, balances())
+
+	setInstances(&discovery.Instance{Addr: "http://localhost:8918", Zone: "us"})
+	assert.Eventually(t, func() bool {
+		_, ok := balances()["staging@a"]
+		return !ok
+	}, time.Second, 20*time.Millisecond)
+	assert.Equal(t, map[string]bool{"staging@b": true}, balances())
+
+	setInstances()
+	assert.Eventually(t, func() bool {
+		_, err := c.Invoke("Balance")
+		return errors.Is(err, client.ErrTransport)
+	}, time.Second, 20*time.Millisecond)
+}
+
+func TestCloseStopsWatches(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&StagingAccountingImpl{Server: "a"}),
+		server.Handle(server.Config{Addr: ":8939"}),
+	)
+	var lookups atomic.Int64
+	lookup := func(context.Context, string) ([]*discovery.Instance, error) {
+		lookups.Add(1)
+		return []*discovery.Instance{{Addr: "http://localhost:8939"}}, nil
+	}
+	var c = &StagingAccountingInvoker{}
+	a := ioc.RunTest(t,
+		app.SetComponents(c),
+		client.Remote(client.Config{
+			Discovery: discovery.Func(lookup, 10*time.Millisecond),
+		}),
+	)
+	assert.Eventually(t, func() bool {
+		return lookups.Load() > 2
+	}, time.Second, 10*time.Millisecond)
+	assert.NoError(t, client.Close(a))
+	closed := lookups.Load()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, closed, lookups.Load())
+
+	// the invoker keeps the servers it last discovered
+	result, err := c.Invoke("Balance")
+	assert.NoError(t, err)
+	assert.Equal(t, "staging@a", result[0])
+}
+
+func TestProbeTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+	start := time.Now()
+	_, err := ioc.Run(
+		app.SetRegistry(registry.NewRegistry()),
+		app.SetComponents(&StagingAccountingInvoker{}),
+		client.Remote(client.Config{
+			Servers: []client.ServerConfig{{Addr: srv.URL}},
+			Timeout: 100 * time.Millisecond,
+		}),
+	)
+	assert.ErrorContains(t, err, "Timeout")
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,186 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/codec"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/go-resty/resty/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRemoteErrors(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&FailingImpl{}),
+		server.Handle(server.Config{
+			Addr: ":8901",
+		}),
+	)
+	for _, name := range codec.Names() {
+		t.Run(name, func(t *testing.T) {
+			var c = &FailingInvoker{}
+			ioc.RunTest(t,
+				app.SetComponents(c),
+				client.Remote(client.Config{
+					Servers: []client.ServerConfig{{Addr: "http://localhost:8901"}},
+					Codecs:  []string{name},
+				}),
+			)
+			// remote errors are results, the invocation itself succeeds
+			find := func(key string) error {
+				results, err := c.Invoke("Find", key)
+				assert.NoError(t, err)
+				resultErr, _ := results[1].(error)
+				return resultErr
+			}
+			t.Run("Sentinel", func(t *testing.T) {
+				err := find("sentinel")
+				assert.Same(t, ErrNotFound, err)
+			})
+			t.Run("WrappedSentinel", func(t *testing.T) {
+				err := find("wrapped")
+				assert.True(t, errors.Is(err, ErrNotFound))
+				assert.Equal(t, "find wrapped: not found", err.Error())
+				var remoteErr *dto.RemoteError
+				assert.True(t, errors.As(err, &remoteErr))
+				assert.Equal(t, "*fmt.wrapError", remoteErr.Type)
+			})
+			t.Run("Joined", func(t *testing.T) {
+				for _, key := range []string{"joined", "multi"} {
+					err := find(key)
+					assert.True(t, errors.Is(err, ErrNotFound), key)
+					var quotaErr *QuotaError
+					assert.True(t, errors.As(err, &quotaErr), key)
+					assert.Equal(t, "disk", quotaErr.Resource)
+				}
+			})
+			t.Run("Unregistered", func(t *testing.T) {
+				err := find("plain")
+				var remoteErr *dto.RemoteError
+				assert.True(t, errors.As(err, &remoteErr))
+				assert.Equal(t, "PLAIN", remoteErr.Code)
+				assert.Equal(t, "unregistered PLAIN", remoteErr.Error())
+			})
+			t.Run("ErrorType", func(t *testing.T) {
+				results, err := c.Invoke("Consume", "disk")
+				assert.NoError(t, err)
+				err, _ = results[0].(error)
+				var quotaErr *QuotaError
+				assert.True(t, errors.As(err, &quotaErr))
+				assert.Equal(t, "disk", quotaErr.Resource)
+				assert.Equal(t, int64(1<<60), quotaErr.Limit)
+				assert.True(t, errors.Is(err, ErrNotFound))
+
+				var remoteErr *dto.RemoteError
+				assert.True(t, errors.As(err, &remoteErr))
+				assert.Equal(t, "consume: quota of disk exceeded (1152921504606846976)", remoteErr.Message)
+			})
+			t.Run("ErrorParameter", func(t *testing.T) {
+				results, err := c.Invoke("Check", fmt.Errorf("lookup: %w", ErrNotFound))
+				assert.NoError(t, err)
+				assert.Equal(t, true, results[0])
+			})
+		})
+	}
+}
+
+func TestInvokeErrors(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&FailingImpl{}),
+		server.Handle(server.Config{
+			Addr: ":8902",
+		}),
+	)
+	for _, name := range codec.Names() {
+		t.Run(name, func(t *testing.T) {
+			var c = &MismatchedInvoker{}
+			ioc.RunTest(t,
+				app.SetComponents(c),
+				client.Remote(client.Config{
+					Servers: []client.ServerConfig{{Addr: "http://localhost:8902"}},
+					Codecs:  []string{name},
+					Timeout: 100 * time.Millisecond,
+				}),
+			)
+			t.Run("Validation", func(t *testing.T) {
+				_, err := c.Invoke("Find", 1)
+				assert.True(t, errors.Is(err, client.ErrValidation), "%v", err)
+				var invokeErr *client.InvokeError
+				assert.True(t, errors.As(err, &invokeErr))
+				assert.Equal(t, client.KindValidation, invokeErr.Kind)
+				assert.Equal(t, 400, invokeErr.StatusCode)
+				assert.Equal(t, "http://localhost:8902", invokeErr.Addr)
+				var validateErr *dto.ValidateError
+				assert.True(t, errors.As(err, &validateErr))
+				assert.Equal(t, "string", validateErr.RequiredParamKind)
+				assert.Equal(t, "int", validateErr.RequestParamKind)
+			})
+			t.Run("Conversion", func(t *testing.T) {
+				_, err := c.Invoke("Count", []string{"a"})
+				assert.True(t, errors.Is(err, client.ErrConversion), "%v", err)
+				var convertErr *dto.ConvertError
+				assert.True(t, errors.As(err, &convertErr))
+				assert.Contains(t, convertErr.Err, "parameter[1]")
+			})
+			t.Run("Timeout", func(t *testing.T) {
+				results, err := c.Invoke("Sleep", time.Second)
+				assert.True(t, errors.Is(err, client.ErrTimeout), "%v", err)
+				assert.False(t, errors.Is(err, client.ErrTransport))
+				assert.Equal(t, false, results[0])
+			})
+		})
+	}
+}
+
+func TestPanicRecovery(t *testing.T) {
+	for port, disableStack := range map[int]bool{8903: false, 8904: true} {
+		ioc.RunTest(t,
+			app.SetComponents(&FailingImpl{}),
+			server.Handle(server.Config{
+				Addr:              fmt.Sprintf(":%d", port),
+				DisablePanicStack: lo.ToPtr(disableStack),
+			}),
+		)
+		var c = &FailingInvoker{}
+		ioc.RunTest(t,
+			app.SetComponents(c),
+			client.Remote(client.Config{
+				Servers: []client.ServerConfig{{Addr: fmt.Sprintf("http://localhost:%d", port)}},
+			}),
+		)
+		_, err := c.Invoke("Explode", "boom")
+		assert.True(t, errors.Is(err, client.ErrPanic), "%v", err)
+		var invokeErr *client.InvokeError
+		assert.True(t, errors.As(err, &invokeErr))
+		assert.Equal(t, 500, invokeErr.StatusCode)
+		var panicErr *dto.PanicError
+		assert.True(t, errors.As(err, &panicErr))
+		assert.Equal(t, "Failing", panicErr.ServiceId)
+		assert.Equal(t, "Explode", panicErr.Method)
+		assert.Equal(t, "boom", panicErr.Message)
+		if disableStack {
+			assert.Empty(t, panicErr.Stack)
+		} else {
+			assert.Contains(t, panicErr.Stack, "Explode")
+		}
+
+		results, err := c.Invoke("Find", "ok")
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", results[0])
+
+		var metrics []*dto.MethodMetrics
+		_, err = resty.New().R().SetResult(&metrics).Get(fmt.Sprintf("http://localhost:%d/metrics", port))
+		assert.NoError(t, err)
+		explode, _ := lo.Find(metrics, func(m *dto.MethodMetrics) bool { return m.Method == "Explode" })
+		assert.Equal(t, &dto.MethodMetrics{ServiceKey: dto.ServiceKey{ServiceId: "Failing"}, Method: "Explode", Calls: 1, Failures: 1, Panics: 1}, explode)
+		find, _ := lo.Find(metrics, func(m *dto.MethodMetrics) bool { return m.Method == "Find" })
+		assert.Equal(t, &dto.MethodMetrics{ServiceKey: dto.ServiceKey{ServiceId: "Failing"}, Method: "Find", Calls: 1}, find)
+	}
+}
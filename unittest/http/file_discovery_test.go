@@ -0,0 +1,71 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/discovery"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileDiscovery(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&StagingAccountingImpl{Server: "a"}, &WaiterImpl{interrupted: make(chan error, 1)}),
+		server.Handle(server.Config{Addr: ":8924"}),
+	)
+	ioc.RunTest(t,
+		app.SetComponents(&StagingAccountingImpl{Server: "b"}),
+		server.Handle(server.Config{Addr: ":8925"}),
+	)
+	path := filepath.Join(t.TempDir(), "services.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+Accounting:
+  - addr: http://localhost:8924
+Waiter:
+  - addr: http://localhost:8924
+`), 0644))
+	var (
+		accounting = &StagingAccountingInvoker{}
+		waiter     = &WaiterInvoker{}
+	)
+	ioc.RunTest(t,
+		app.SetComponents(accounting, waiter),
+		client.Remote(client.Config{Discovery: discovery.File(path)}),
+	)
+	result, err := accounting.Invoke("Balance")
+	assert.NoError(t, err)
+	assert.Equal(t, "staging@a", result[0])
+
+	var drained = make(chan []any, 1)
+	go func() {
+		result, err := waiter.Invoke("Wait", context.Background(), 300*time.Millisecond)
+		assert.NoError(t, err)
+		drained <- result
+	}()
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, os.WriteFile(path, []byte(`
+Accounting:
+  - addr: http://localhost:8925
+Waiter: []
+`), 0644))
+	assert.Eventually(t, func() bool {
+		result, err := accounting.Invoke("Balance")
+		return err == nil && result[0] == "staging@b"
+	}, time.Second, 10*time.Millisecond)
+	_, err = waiter.Invoke("Wait", context.Background(), time.Millisecond)
+	assert.True(t, errors.Is(err, client.ErrTransport), "%v", err)
+	assert.Equal(t, []any{true}, <-drained)
+
+	assert.NoError(t, os.WriteFile(path, []byte("Accounting: [\n"), 0644))
+	time.Sleep(200 * time.Millisecond)
+	result, err = accounting.Invoke("Balance")
+	assert.NoError(t, err)
+	assert.Equal(t, "staging@b", result[0])
+}
@@ -0,0 +1,189 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/idempotency"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/go-resty/resty/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIdempotency(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&JournalImpl{Delay: 100 * time.Millisecond}),
+		server.Handle(server.Config{
+			Addr:        ":8932",
+			Idempotency: server.Idempotency{Store: idempotency.Memory()},
+			RateLimits: server.RateLimits{CallerKey: func(r *http.Request) string {
+				return r.Header.Get("X-Caller")
+			}},
+		}),
+	)
+	var c = &JournalInvoker{}
+	ioc.RunTest(t,
+		app.SetComponents(c),
+		client.Remote(client.Config{
+			Servers: []client.ServerConfig{{Addr: "http://localhost:8932"}},
+		}),
+	)
+	appendWith := func(key string) (int, error) {
+		ctx := context.Background()
+		if key != "" {
+			ctx = client.WithIdempotencyKey(ctx, key)
+		}
+		result, err := c.Invoke("Append", ctx, 10)
+		if err != nil {
+			return 0, err
+		}
+		return result[0].(int), nil
+	}
+
+	t.Run("Replayed", func(t *testing.T) {
+		first, err := appendWith("a")
+		assert.NoError(t, err)
+		again, err := appendWith("a")
+		assert.NoError(t, err)
+		assert.Equal(t, first, again)
+		other, err := appendWith("")
+		assert.NoError(t, err)
+		assert.Equal(t, first+1, other)
+	})
+	t.Run("ConcurrentDuplicates", func(t *testing.T) {
+		var (
+			wg      sync.WaitGroup
+			results = make([]int, 3)
+		)
+		for i := range results {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var err error
+				results[i], err = appendWith("b")
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, results[0], results[1])
+		assert.Equal(t, results[0], results[2])
+		next, err := appendWith("")
+		assert.NoError(t, err)
+		assert.Equal(t, results[0]+1, next)
+	})
+	t.Run("Metrics", func(t *testing.T) {
+		var metrics []*dto.MethodMetrics
+		_, err := resty.New().R().SetResult(&metrics).Get("http://localhost:8932" + constant.RouteMetrics)
+		assert.NoError(t, err)
+		journal, _ := lo.Find(metrics, func(m *dto.MethodMetrics) bool { return m.Method == "Append" })
+		assert.Equal(t, int64(3), journal.Replayed)
+		assert.Equal(t, int64(4), journal.Calls)
+	})
+	t.Run("OtherPayload", func(t *testing.T) {
+		ctx := client.WithIdempotencyKey(context.Background(), "c")
+		_, err := c.Invoke("Append", ctx, 10)
+		assert.NoError(t, err)
+		_, err = c.Invoke("Append", ctx, 20)
+		var invokeErr *client.InvokeError
+		assert.True(t, errors.As(err, &invokeErr), "%v", err)
+		assert.Equal(t, http.StatusUnprocessableEntity, invokeErr.StatusCode)
+	})
+	t.Run("OtherCaller", func(t *testing.T) {
+		var other = &JournalInvoker{}
+		ioc.RunTest(t,
+			app.SetComponents(other),
+			client.Remote(client.Config{
+				Servers: []client.ServerConfig{{Addr: "http://localhost:8932"}},
+				Headers: map[string]string{"X-Caller": "other"},
+			}),
+		)
+		ctx := client.WithIdempotencyKey(context.Background(), "a")
+		first, err := appendWith("a")
+		assert.NoError(t, err)
+		result, err := other.Invoke("Append", ctx, 10)
+		assert.NoError(t, err)
+		assert.Greater(t, result[0].(int), first)
+	})
+}
+
+func TestIdempotencyBeforeRateLimits(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&JournalImpl{}),
+		server.Handle(server.Config{
+			Addr:        ":8940",
+			Idempotency: server.Idempotency{Store: idempotency.Memory()},
+			RateLimits: server.RateLimits{
+				Methods: map[string]server.RateLimit{"Journal.Append": {Rate: 0.1, Burst: 1}},
+			},
+		}),
+	)
+	var c = &JournalInvoker{}
+	ioc.RunTest(t,
+		app.SetComponents(c),
+		client.Remote(client.Config{
+			Servers: []client.ServerConfig{{Addr: "http://localhost:8940"}},
+		}),
+	)
+	ctx := client.WithIdempotencyKey(context.Background(), "a")
+	first, err := c.Invoke("Append", ctx, 10)
+	assert.NoError(t, err)
+	// replays take no token
+	again, err := c.Invoke("Append", ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, first, again)
+	_, err = c.Invoke("Append", context.Background(), 10)
+	assert.True(t, errors.Is(err, client.ErrThrottled), "%v", err)
+}
+
+// TestRetryLostResponse loses the response of the first call in a gateway,
+// the retry is answered with the stored response instead of running the
+// method again.
+func TestRetryLostResponse(t *testing.T) {
+	var journal = &JournalImpl{}
+	ioc.RunTest(t,
+		app.SetComponents(journal),
+		server.Handle(server.Config{
+			Addr:        ":8947",
+			Idempotency: server.Idempotency{Store: idempotency.Memory()},
+		}),
+	)
+	target, _ := url.Parse("http://localhost:8947")
+	var lost atomic.Bool
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.ModifyResponse = func(response *http.Response) error {
+		if response.Request.Method == http.MethodPost && lost.CompareAndSwap(false, true) {
+			return errors.New("response lost")
+		}
+		return nil
+	}
+	gateway := httptest.NewServer(proxy)
+	defer gateway.Close()
+
+	var c = &JournalInvoker{}
+	ioc.RunTest(t,
+		app.SetComponents(c),
+		client.Remote(client.Config{
+			Servers: []client.ServerConfig{{Addr: gateway.URL}},
+			Retry:   client.RetryPolicy{Attempts: 1, Backoff: time.Millisecond},
+		}),
+	)
+	result, err := c.Invoke("Append", context.Background(), 10)
+	assert.NoError(t, err)
+	assert.True(t, lost.Load())
+	assert.Equal(t, 1, result[0])
+	assert.Equal(t, int64(1), journal.entries.Load())
+}
@@ -42,6 +42,30 @@ func (s *ServerComponentInvoker) SumF(base, add float64) float64 {
 	return anies[0].(float64)
 }
 
+func (s *ServerComponentInvoker) SumI64(base, add int64) int64 {
+	anies, err := s.Invoke("SumI64", base, add)
+	if err != nil {
+		panic(err)
+	}
+	return anies[0].(int64)
+}
+
+func (s *ServerComponentInvoker) SumU64(base, add uint64) uint64 {
+	anies, err := s.Invoke("SumU64", base, add)
+	if err != nil {
+		panic(err)
+	}
+	return anies[0].(uint64)
+}
+
+func (s *ServerComponentInvoker) SumI8(base, add int8) int8 {
+	anies, err := s.Invoke("SumI8", base, add)
+	if err != nil {
+		panic(err)
+	}
+	return anies[0].(int8)
+}
+
 func (s *ServerComponentInvoker) And(b1, b2 bool) bool {
 	anies, err := s.Invoke("And", b1, b2)
 	if err != nil {
@@ -108,7 +132,12 @@ func (s *ServerComponentInvoker) AddTimePtr(t1 *time.Time, duration *time.Durati
 
 func (s *ServerComponentInvoker) ConvertError(msg string) (string, error) {
 	anies, err := s.Invoke("ConvertError", msg)
-	return anies[0].(string), err
+	if err != nil {
+		return "", err
+	}
+	// the remote error is a result of the method
+	resultErr, _ := anies[1].(error)
+	return anies[0].(string), resultErr
 }
 
 func (s *ServerComponentInvoker) WithContext(ctx context.Context) string {
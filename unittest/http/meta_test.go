@@ -0,0 +1,53 @@
+package http
+
+import (
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// TestMetaWithoutCodecs calls a server whose meta route does not advertise
+// codecs, as the servers before the codecs were added: the client falls back
+// to JSON.
+func TestMetaWithoutCodecs(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&LegacyGreeterImpl{}),
+		server.Handle(server.Config{Addr: ":8943"}),
+	)
+	target, _ := url.Parse("http://localhost:8943")
+	var (
+		mu           sync.Mutex
+		contentTypes []string
+	)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.ModifyResponse = func(response *http.Response) error {
+		response.Header.Del(constant.HeaderCodecs)
+		if response.Request.Method == http.MethodPost {
+			mu.Lock()
+			contentTypes = append(contentTypes, response.Request.Header.Get("Content-Type"))
+			mu.Unlock()
+		}
+		return nil
+	}
+	legacy := httptest.NewServer(proxy)
+	defer legacy.Close()
+
+	invoker := &LegacyGreeterInvoker{}
+	ioc.RunTest(t,
+		app.SetComponents(invoker),
+		client.Remote(client.Config{Servers: []client.ServerConfig{{Addr: legacy.URL}}}),
+	)
+	result, err := invoker.Invoke("Hello", "kid")
+	assert.NoError(t, err)
+	assert.Equal(t, "hi kid", result[0])
+	assert.Equal(t, []string{"application/json"}, contentTypes)
+}
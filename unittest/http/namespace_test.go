@@ -0,0 +1,85 @@
+package http
+
+import (
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/ioc/registry"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNamespaces(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&StagingAccountingImpl{Server: "a"}, &ProdAccountingImpl{}, &DefaultAccountingImpl{}),
+		server.Handle(server.Config{Addr: ":8915"}),
+	)
+	ioc.RunTest(t,
+		app.SetComponents(&StagingAccountingImpl{Server: "b"}),
+		server.Handle(server.Config{Addr: ":8916"}),
+	)
+
+	t.Run("Meta", func(t *testing.T) {
+		var meta []*dto.ServerInfo
+		_, err := resty.New().R().SetResult(&meta).Get("http://localhost:8915" + constant.RouteMeta)
+		assert.NoError(t, err)
+		var keys []string
+		for _, info := range meta {
+			keys = append(keys, info.ServiceKey.String())
+		}
+		assert.ElementsMatch(t, []string{"staging/payments/Accounting", "prod/payments/Accounting", "Accounting"}, keys)
+	})
+	t.Run("Resolve", func(t *testing.T) {
+		var (
+			staging = &StagingAccountingInvoker{}
+			prod    = &ProdAccountingInvoker{}
+			plain   = &AccountingInvoker{}
+		)
+		ioc.RunTest(t,
+			app.SetComponents(staging, prod, plain),
+			client.Remote(client.Config{
+				Servers: []client.ServerConfig{{Addr: "http://localhost:8915"}, {Addr: "http://localhost:8916"}},
+			}),
+		)
+		var seen = map[string]bool{}
+		for i := 0; i < 4; i++ {
+			result, err := staging.Invoke("Balance")
+			assert.NoError(t, err)
+			seen[result[0].(string)] = true
+		}
+		assert.Equal(t, map[string]bool{"staging@a": true, "staging@b": true}, seen)
+
+		result, err := prod.Invoke("Balance")
+		assert.NoError(t, err)
+		assert.Equal(t, "prod", result[0])
+
+		result, err = plain.Invoke("Balance")
+		assert.NoError(t, err)
+		assert.Equal(t, "default", result[0])
+	})
+	t.Run("ServerConfig", func(t *testing.T) {
+		var staging = &StagingAccountingInvoker{}
+		ioc.RunTest(t,
+			app.SetComponents(staging),
+			client.Remote(client.Config{
+				Servers: []client.ServerConfig{{Addr: "http://localhost:8915", Namespace: "staging", Group: "payments"}},
+			}),
+		)
+		result, err := staging.Invoke("Balance")
+		assert.NoError(t, err)
+		assert.Equal(t, "staging@a", result[0])
+
+		_, err = ioc.Run(
+			app.SetRegistry(registry.NewRegistry()),
+			app.SetComponents(&ProdAccountingInvoker{}),
+			client.Remote(client.Config{
+				Servers: []client.ServerConfig{{Addr: "http://localhost:8915", Namespace: "staging"}},
+			}),
+		)
+		assert.ErrorContains(t, err, "prod/payments/Accounting: no server exports it")
+	})
+}
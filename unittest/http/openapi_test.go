@@ -0,0 +1,40 @@
+package http
+
+import (
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/go-kid/remote-ioc/http/openapi"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestOpenAPI(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&AccountsImpl{}),
+		server.Handle(server.Config{
+			Addr: ":8908",
+		}),
+	)
+	var doc = &openapi.Document{}
+	assert.Eventually(t, func() bool {
+		response, err := resty.New().R().
+			SetResult(doc).
+			Get("http://localhost:8908" + constant.RouteOpenAPI)
+		return err == nil && response.StatusCode() == 200
+	}, 2*time.Second, 20*time.Millisecond)
+	assert.Contains(t, doc.Paths, "/component/Accounts/methods/Rename")
+	op := doc.Paths["/component/Accounts/methods/Register"].Post
+	if assert.NotNil(t, op) {
+		assert.Equal(t, []string{"Accounts"}, op.Tags)
+		assert.Len(t, op.RequestBody.Content, 3)
+	}
+	signup := doc.Components.Schemas["http.Signup"]
+	if assert.NotNil(t, signup) {
+		assert.Equal(t, "email", signup.Properties["email"].Format)
+		assert.Equal(t, []string{"name", "email"}, signup.Required)
+	}
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/ioc/registry"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/go-resty/resty/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPlayground(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&AccountsImpl{}),
+		server.Handle(server.Config{
+			Addr:         ":8909",
+			Authenticate: server.BasicAuth("admin", "secret"),
+			Playground:   lo.ToPtr(true),
+		}),
+	)
+	var addr = "http://localhost:8909"
+	assert.Eventually(t, func() bool {
+		response, err := resty.New().R().Get(addr + constant.RouteHealth)
+		return err == nil && response.StatusCode() == http.StatusOK
+	}, 2*time.Second, 20*time.Millisecond)
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		for _, route := range []string{constant.RoutePlayground, constant.RouteMeta, constant.RouteOpenAPI} {
+			response, err := resty.New().R().Get(addr + route)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusUnauthorized, response.StatusCode(), route)
+			assert.Equal(t, `Basic realm="remote-ioc"`, response.Header().Get("WWW-Authenticate"))
+		}
+		response, err := resty.New().R().
+			SetBasicAuth("admin", "wrong").
+			SetHeader("Content-Type", "application/json").
+			SetBody(`{"params":[]}`).
+			Post(addr + "/component/Accounts/methods/Register")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode())
+	})
+	t.Run("Page", func(t *testing.T) {
+		response, err := resty.New().R().
+			SetBasicAuth("admin", "secret").
+			Get(addr + constant.RoutePlayground)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode())
+		assert.Contains(t, response.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, response.String(), "remote-ioc playground")
+	})
+	t.Run("Client", func(t *testing.T) {
+		var c = &AccountsInvoker{}
+		ioc.RunTest(t,
+			app.SetComponents(c),
+			client.Remote(client.Config{
+				Servers: []client.ServerConfig{{Addr: addr}},
+				Headers: map[string]string{"Authorization": "Basic YWRtaW46c2VjcmV0"},
+			}),
+		)
+		result, err := c.Invoke("Register", &Signup{Name: "kid", Email: "kid@example.com", Age: 20})
+		assert.NoError(t, err)
+		assert.Equal(t, "welcome kid", result[0])
+	})
+}
+
+func TestPlaygroundDisabled(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&AccountsImpl{}),
+		server.Handle(server.Config{
+			Addr: ":8910",
+		}),
+	)
+	assert.Eventually(t, func() bool {
+		response, err := resty.New().R().Get("http://localhost:8910" + constant.RoutePlayground)
+		return err == nil && response.StatusCode() == http.StatusNotFound
+	}, 2*time.Second, 20*time.Millisecond)
+
+	_, err := ioc.Run(
+		app.SetRegistry(registry.NewRegistry()),
+		app.SetComponents(&AccountsImpl{}),
+		server.Handle(server.Config{
+			Addr:       ":8911",
+			Playground: lo.ToPtr(true),
+		}),
+	)
+	assert.ErrorContains(t, err, "requires an Authenticate")
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/codec"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type unregistered struct{}
+
+func (unregistered) Area() float64 { return 0 }
+
+func TestPolymorphic(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&PolymorphicImpl{}),
+		server.Handle(server.Config{
+			Addr: ":8899",
+		}),
+	)
+	drawing := &Drawing{
+		Name:   "d",
+		Shapes: []Shape{Circle{R: 1}, &Rect{W: 2, H: 3}, nil},
+		ByName: map[string]Shape{"c": Circle{R: 2}},
+		Main:   &Rect{W: 1, H: 1},
+		Extra:  []any{1, uint8(2), "3", time.Duration(4), map[string]any{"five": int64(5)}},
+	}
+
+	var tests = []struct {
+		method string
+		in     any
+		want   any
+	}{
+		{"Largest", []Shape{Circle{R: 1}, &Rect{W: 2, H: 3}}, Shape(&Rect{W: 2, H: 3})},
+		{"Largest", []Shape{}, Shape(nil)},
+		{"Describe", Circle{R: 1.5}, "circle(1.5)"},
+		{"Echo", 42, 42},
+		{"Echo", Circle{R: 1}, Circle{R: 1}},
+		{"Echo", nil, nil},
+		{"EchoDrawing", drawing, drawing},
+	}
+
+	for _, name := range codec.Names() {
+		t.Run(name, func(t *testing.T) {
+			var c = &PolymorphicInvoker{}
+			ioc.RunTest(t,
+				app.SetComponents(c),
+				client.Remote(client.Config{
+					Servers: []client.ServerConfig{{Addr: "http://localhost:8899"}},
+					Codecs:  []string{name},
+				}),
+			)
+			for _, tt := range tests {
+				t.Run(tt.method, func(t *testing.T) {
+					results, err := c.Invoke(tt.method, tt.in)
+					assert.NoError(t, err)
+					assert.Equal(t, tt.want, results[0])
+				})
+			}
+			t.Run("Unregistered", func(t *testing.T) {
+				_, err := c.Invoke("Largest", []Shape{unregistered{}})
+				assert.Error(t, err)
+			})
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package http
+
+import (
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/ioc/configure/binder"
+	"github.com/go-kid/ioc/configure/loader"
+	"github.com/go-kid/ioc/registry"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/go-resty/resty/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProperties(t *testing.T) {
+	t.Setenv("REMOTE_IOC_SERVER_ADDR", ":8926")
+	ioc.RunTest(t,
+		app.SetConfigLoader(loader.NewRawLoader()),
+		app.SetConfig(`
+remote-ioc:
+  server:
+    addr: :9999
+    codecs: [json]
+    auth:
+      token: secret
+`),
+		app.SetComponents(&StagingAccountingImpl{Server: "a"}),
+		server.Handle(server.Config{}),
+	)
+
+	t.Run("YAML", func(t *testing.T) {
+		var c = &StagingAccountingInvoker{}
+		ioc.RunTest(t,
+			app.SetConfigLoader(loader.NewRawLoader()),
+			app.SetConfig(`
+remote-ioc:
+  client:
+    timeout: 1s
+    headers:
+      Authorization: Bearer secret
+    servers:
+      - addr: http://localhost:8926
+`),
+			app.SetComponents(c),
+			client.Remote(client.Config{}),
+		)
+		result, err := c.Invoke("Balance")
+		assert.NoError(t, err)
+		assert.Equal(t, "staging@a", result[0])
+	})
+	t.Run("Properties", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "services.yaml")
+		assert.NoError(t, os.WriteFile(path, []byte("Accounting:\n  - addr: http://localhost:8926\n"), 0644))
+		t.Setenv("REMOTE_IOC_CLIENT_HEADERS", "Authorization=Bearer secret")
+		var c = &StagingAccountingInvoker{}
+		ioc.RunTest(t,
+			app.SetConfigLoader(loader.NewRawLoader()),
+			app.SetConfigBinder(binder.NewViperBinder("properties")),
+			app.SetConfig("remote-ioc.client.discovery.file="+path+"\n"),
+			app.SetComponents(c),
+			client.Remote(client.Config{}),
+		)
+		result, err := c.Invoke("Balance")
+		assert.NoError(t, err)
+		assert.Equal(t, "staging@a", result[0])
+	})
+	t.Run("CodePrecedence", func(t *testing.T) {
+		var c = &StagingAccountingInvoker{}
+		ioc.RunTest(t,
+			app.SetConfigLoader(loader.NewRawLoader()),
+			app.SetConfig(`
+remote-ioc:
+  client:
+    servers:
+      - addr: http://localhost:1
+`),
+			app.SetComponents(c),
+			client.Remote(client.Config{
+				Servers: []client.ServerConfig{{Addr: "http://localhost:8926"}},
+				Headers: map[string]string{"Authorization": "Bearer secret"},
+				Timeout: time.Second,
+			}),
+		)
+		result, err := c.Invoke("Balance")
+		assert.NoError(t, err)
+		assert.Equal(t, "staging@a", result[0])
+	})
+}
+
+func TestInvalidProperties(t *testing.T) {
+	run := func(config string, option app.SettingOption) error {
+		_, err := ioc.Run(
+			app.SetRegistry(registry.NewRegistry()),
+			app.SetConfigLoader(loader.NewRawLoader()),
+			app.SetConfig(config),
+			app.SetComponents(&StagingAccountingImpl{}, &StagingAccountingInvoker{}),
+			option,
+		)
+		return err
+	}
+	for config, message := range map[string]string{
+		"remote-ioc: {server: {addr: localhost}}":                                      `remote-ioc.server.addr: invalid address "localhost"`,
+		"remote-ioc: {server: {addr: ':8927', codecs: [xml]}}":                         `remote-ioc.server.codecs: unknown codec "xml"`,
+		"remote-ioc: {server: {addr: ':8927', auth: {password: secret}}}":              "remote-ioc.server.auth.username is required",
+		"remote-ioc: {server: {addr: ':8927', registry: {addr: registry}}}":            `remote-ioc.server.registry.addr: invalid URL "registry"`,
+		"remote-ioc: {server: {addr: ':8927', admin: {addr: ':8928'}}}":                "the admin routes require an Authenticate",
+		"remote-ioc: {server: {addr: ':8927', idempotency: {store: redis}}}":           `remote-ioc.server.idempotency.store: unknown store "redis"`,
+		"remote-ioc: {server: {addr: ':8927', bulkheads: {default: {max-queue: -1}}}}": "remote-ioc.server.bulkheads.default: negative max-concurrent",
+	} {
+		assert.ErrorContains(t, run(config, server.Handle(server.Config{})), message, config)
+	}
+	for config, message := range map[string]string{
+		"remote-ioc: {client: {servers: [{addr: 'localhost:8080'}]}}":             `remote-ioc.client.servers[0].addr: invalid URL "localhost:8080"`,
+		"remote-ioc: {client: {discovery: {file: a.yaml, registry: 'http://r'}}}": "remote-ioc.client.discovery: set one of file, registry and dns, not [file registry]",
+		"remote-ioc: {client: {timeout: -1s}}":                                    "remote-ioc.client.timeout: negative timeout -1s",
+		"remote-ioc: {client: {concurrency: {max: 4, backoff: 1.5}}}":             "remote-ioc.client.concurrency.backoff: 1.5 is not in (0, 1)",
+	} {
+		assert.ErrorContains(t, run(config, client.Remote(client.Config{})), message, config)
+	}
+
+	t.Setenv("REMOTE_IOC_SERVER_PLAYGROUND", "maybe")
+	assert.ErrorContains(t, run("remote-ioc: {server: {addr: ':8927'}}", server.Handle(server.Config{})),
+		`REMOTE_IOC_SERVER_PLAYGROUND: invalid bool "maybe"`)
+}
+
+func TestExplicitFalse(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetConfigLoader(loader.NewRawLoader()),
+		app.SetConfig("remote-ioc: {server: {addr: ':8946', playground: true}}"),
+		app.SetComponents(&StagingAccountingImpl{Server: "a"}),
+		server.Handle(server.Config{Playground: lo.ToPtr(false)}),
+	)
+	response, err := resty.New().R().Get("http://localhost:8946" + constant.RoutePlayground)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode())
+}
@@ -0,0 +1,191 @@
+package http
+
+import (
+	"errors"
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/go-resty/resty/v2"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimits(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&StagingAccountingImpl{Server: "a"}, &ProdAccountingImpl{}),
+		server.Handle(server.Config{
+			Addr: ":8928",
+			RateLimits: server.RateLimits{
+				Methods: map[string]server.RateLimit{"Accounting.Balance": {Rate: 1, Burst: 2}},
+			},
+		}),
+	)
+	newInvoker := func(retry client.RetryPolicy) *StagingAccountingInvoker {
+		var c = &StagingAccountingInvoker{}
+		ioc.RunTest(t,
+			app.SetComponents(c),
+			client.Remote(client.Config{
+				Servers: []client.ServerConfig{{Addr: "http://localhost:8928"}},
+				Retry:   retry,
+			}),
+		)
+		return c
+	}
+
+	t.Run("Throttled", func(t *testing.T) {
+		c := newInvoker(client.RetryPolicy{})
+		for i := 0; i < 2; i++ {
+			_, err := c.Invoke("Balance")
+			assert.NoError(t, err)
+		}
+		_, err := c.Invoke("Balance")
+		assert.True(t, errors.Is(err, client.ErrThrottled), "%v", err)
+		var invokeErr *client.InvokeError
+		assert.True(t, errors.As(err, &invokeErr))
+		assert.Equal(t, http.StatusTooManyRequests, invokeErr.StatusCode)
+		assert.Equal(t, time.Second, invokeErr.RetryAfter)
+
+		// the prod namespace has a bucket of its own
+		var prod = &ProdAccountingInvoker{}
+		ioc.RunTest(t,
+			app.SetComponents(prod),
+			client.Remote(client.Config{
+				Servers: []client.ServerConfig{{Addr: "http://localhost:8928"}},
+			}),
+		)
+		result, err := prod.Invoke("Balance")
+		assert.NoError(t, err)
+		assert.Equal(t, "prod", result[0])
+	})
+	t.Run("Admin", func(t *testing.T) {
+		var states []*dto.LimitState
+		_, err := resty.New().R().SetResult(&states).Get("http://localhost:8928" + constant.RouteLimits)
+		assert.NoError(t, err)
+		assert.Len(t, states, 2)
+		assert.Equal(t, "method", states[1].Scope)
+		assert.Equal(t, "staging/payments/Accounting.Balance", states[1].Key)
+		assert.Equal(t, int64(1), states[1].Rejected)
+		assert.Less(t, states[1].Tokens, 1.0)
+
+		var metrics []*dto.MethodMetrics
+		_, err = resty.New().R().SetResult(&metrics).Get("http://localhost:8928" + constant.RouteMetrics)
+		assert.NoError(t, err)
+		balance, _ := lo.Find(metrics, func(m *dto.MethodMetrics) bool {
+			return m.Namespace == "staging" && m.Method == "Balance"
+		})
+		assert.Equal(t, int64(1), balance.Throttled)
+	})
+	t.Run("MaxWait", func(t *testing.T) {
+		c := newInvoker(client.RetryPolicy{Attempts: 3, MaxWait: 100 * time.Millisecond})
+		start := time.Now()
+		_, err := c.Invoke("Balance")
+		assert.True(t, errors.Is(err, client.ErrThrottled), "%v", err)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+	t.Run("Retry", func(t *testing.T) {
+		c := newInvoker(client.RetryPolicy{Attempts: 2})
+		start := time.Now()
+		result, err := c.Invoke("Balance")
+		assert.NoError(t, err)
+		assert.Equal(t, "staging@a", result[0])
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	})
+}
+
+func TestCallerRateLimits(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&StagingAccountingImpl{Server: "a"}),
+		server.Handle(server.Config{
+			Addr: ":8929",
+			RateLimits: server.RateLimits{
+				Caller:  server.RateLimit{Rate: 0.1, Burst: 1},
+				Callers: map[string]server.RateLimit{"vip": {Rate: 100}},
+				CallerKey: func(r *http.Request) string {
+					return r.Header.Get("X-Caller")
+				},
+			},
+		}),
+	)
+	newInvoker := func(caller string) *StagingAccountingInvoker {
+		var c = &StagingAccountingInvoker{}
+		ioc.RunTest(t,
+			app.SetComponents(c),
+			client.Remote(client.Config{
+				Servers: []client.ServerConfig{{Addr: "http://localhost:8929"}},
+				Headers: map[string]string{"X-Caller": caller},
+			}),
+		)
+		return c
+	}
+	var (
+		alice = newInvoker("alice")
+		bob   = newInvoker("bob")
+		vip   = newInvoker("vip")
+	)
+	_, err := alice.Invoke("Balance")
+	assert.NoError(t, err)
+	_, err = alice.Invoke("Balance")
+	assert.True(t, errors.Is(err, client.ErrThrottled), "%v", err)
+	var invokeErr *client.InvokeError
+	assert.True(t, errors.As(err, &invokeErr))
+	assert.Equal(t, 10*time.Second, invokeErr.RetryAfter)
+
+	_, err = bob.Invoke("Balance")
+	assert.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err = vip.Invoke("Balance")
+		assert.NoError(t, err)
+	}
+}
+
+func TestMaxCallers(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&StagingAccountingImpl{Server: "a"}),
+		server.Handle(server.Config{
+			Addr: ":8945",
+			RateLimits: server.RateLimits{
+				Caller:     server.RateLimit{Rate: 0.1, Burst: 1},
+				MaxCallers: 2,
+				CallerKey: func(r *http.Request) string {
+					return r.Header.Get("X-Caller")
+				},
+			},
+		}),
+	)
+	invokers := map[string]*StagingAccountingInvoker{}
+	for _, caller := range []string{"alice", "bob", "carol"} {
+		var c = &StagingAccountingInvoker{}
+		ioc.RunTest(t,
+			app.SetComponents(c),
+			client.Remote(client.Config{
+				Servers: []client.ServerConfig{{Addr: "http://localhost:8945"}},
+				Headers: map[string]string{"X-Caller": caller},
+			}),
+		)
+		invokers[caller] = c
+	}
+	_, err := invokers["alice"].Invoke("Balance")
+	assert.NoError(t, err)
+	_, err = invokers["alice"].Invoke("Balance")
+	assert.True(t, errors.Is(err, client.ErrThrottled), "%v", err)
+
+	// bob and carol push alice out, she is seen as a new caller again
+	for _, caller := range []string{"bob", "carol"} {
+		_, err = invokers[caller].Invoke("Balance")
+		assert.NoError(t, err)
+	}
+	var states []*dto.LimitState
+	_, err = resty.New().R().SetResult(&states).Get("http://localhost:8945" + constant.RouteLimits)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"bob", "carol"}, lo.Map(states, func(s *dto.LimitState, _ int) string {
+		return s.Key
+	}))
+	_, err = invokers["alice"].Invoke("Balance")
+	assert.NoError(t, err)
+}
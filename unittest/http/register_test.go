@@ -0,0 +1,111 @@
+package http
+
+import (
+	"context"
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/ioc/registry"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/discovery"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/naming"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSelfRegistration(t *testing.T) {
+	r, err := naming.New(naming.Config{Sweep: 50 * time.Millisecond})
+	assert.NoError(t, err)
+	defer r.Close()
+	ctx := context.Background()
+
+	a := ioc.RunTest(t,
+		app.SetComponents(&StagingAccountingImpl{Server: "a"}),
+		server.Handle(server.Config{
+			Addr: ":8922",
+			Registry: &server.RegistryConfig{
+				Registrar: r,
+				Advertise: "http://localhost:8922",
+				Zone:      "eu",
+				Metadata:  map[string]string{"build": "42"},
+				TTL:       time.Second,
+			},
+		}),
+	)
+	assert.Eventually(t, func() bool {
+		return len(r.Leases()) == 1
+	}, time.Second, 10*time.Millisecond)
+	lease := r.Leases()[0]
+	assert.Equal(t, &discovery.Instance{Addr: "http://localhost:8922", Zone: "eu", Metadata: map[string]string{"build": "42"}}, lease.Instance)
+	assert.Equal(t, []string{"Accounting"}, lease.ServiceIds())
+	assert.Equal(t, []dto.ServiceKey{{Namespace: "staging", Group: "payments", ServiceId: "Accounting"}}, lease.Services)
+
+	t.Run("Heartbeat", func(t *testing.T) {
+		time.Sleep(1500 * time.Millisecond)
+		leases := r.Leases()
+		assert.Len(t, leases, 1)
+		assert.Equal(t, lease.Id, leases[0].Id)
+	})
+	t.Run("Discovery", func(t *testing.T) {
+		var c = &StagingAccountingInvoker{}
+		ioc.RunTest(t,
+			app.SetComponents(c),
+			client.Remote(client.Config{Discovery: r}),
+		)
+		result, err := c.Invoke("Balance")
+		assert.NoError(t, err)
+		assert.Equal(t, "staging@a", result[0])
+	})
+	t.Run("RegisterAgain", func(t *testing.T) {
+		assert.NoError(t, r.Deregister(ctx, lease.Id))
+		assert.Eventually(t, func() bool {
+			leases := r.Leases()
+			return len(leases) == 1 && leases[0].Id != lease.Id
+		}, time.Second, 10*time.Millisecond)
+	})
+	t.Run("Shutdown", func(t *testing.T) {
+		assert.NoError(t, server.Shutdown(ctx, a))
+		assert.Empty(t, r.Leases())
+		_, err := naming.NewClient(naming.ClientConfig{Addr: "http://localhost:8922"}).Instances(ctx, "Accounting", 0, 0)
+		assert.ErrorContains(t, err, "refused")
+	})
+}
+
+func TestRegistryRequiresRegistrar(t *testing.T) {
+	_, err := ioc.Run(
+		app.SetRegistry(registry.NewRegistry()),
+		app.SetComponents(&StagingAccountingImpl{}),
+		server.Handle(server.Config{Addr: ":8923", Registry: &server.RegistryConfig{}}),
+	)
+	assert.ErrorContains(t, err, "Registrar")
+}
+
+// stuckRegistrar ignores the contexts of the registrations and never answers.
+type stuckRegistrar struct {
+	naming.Registrar
+	release chan struct{}
+}
+
+func (r *stuckRegistrar) Register(context.Context, *naming.Registration) (*naming.Lease, error) {
+	<-r.release
+	return nil, context.Canceled
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	registrar := &stuckRegistrar{release: make(chan struct{})}
+	defer close(registrar.release)
+	a := ioc.RunTest(t,
+		app.SetComponents(&StagingAccountingImpl{}),
+		server.Handle(server.Config{
+			Addr:     ":8938",
+			Registry: &server.RegistryConfig{Registrar: registrar, Advertise: "http://localhost:8938"},
+		}),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.ErrorContains(t, server.Shutdown(ctx, a), "deadline exceeded")
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -6,9 +6,11 @@ import (
 	"github.com/go-kid/ioc"
 	"github.com/go-kid/ioc/app"
 	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/codec"
 	"github.com/go-kid/remote-ioc/http/server"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
+	"math"
 	"testing"
 	"time"
 )
@@ -30,6 +32,14 @@ func TestRemoteIOC(t *testing.T) {
 		startServer(t, i)
 		ports = append(ports, i)
 	}
+	for _, name := range []string{codec.JSON, codec.MessagePack, codec.CBOR} {
+		t.Run(name, func(t *testing.T) {
+			testRemoteIOC(t, ports, name)
+		})
+	}
+}
+
+func testRemoteIOC(t *testing.T, ports []int, codecName string) {
 	var s = &ServerComponentImpl{}
 
 	var c = &ClientApp{}
@@ -42,7 +52,8 @@ func TestRemoteIOC(t *testing.T) {
 					RoutePrefix: "",
 				}
 			}),
-			Debug: false,
+			Debug:  lo.ToPtr(false),
+			Codecs: []string{codecName},
 			LoadBalance: func(servers []*client.ServerInfo) int {
 				var (
 					minIndex int
@@ -69,6 +80,16 @@ func TestRemoteIOC(t *testing.T) {
 	t.Run("SumF", func(t *testing.T) {
 		assert.Equal(t, c.C.SumF(1.2, 3.4), s.SumF(1.2, 3.4))
 	})
+	t.Run("SumI64", func(t *testing.T) {
+		assert.Equal(t, c.C.SumI64(math.MaxInt64-1, 1), s.SumI64(math.MaxInt64-1, 1))
+		assert.Equal(t, c.C.SumI64(math.MinInt64+1, -1), s.SumI64(math.MinInt64+1, -1))
+	})
+	t.Run("SumU64", func(t *testing.T) {
+		assert.Equal(t, c.C.SumU64(math.MaxUint64-1, 1), s.SumU64(math.MaxUint64-1, 1))
+	})
+	t.Run("SumI8", func(t *testing.T) {
+		assert.Equal(t, c.C.SumI8(math.MinInt8, math.MaxInt8), s.SumI8(math.MinInt8, math.MaxInt8))
+	})
 	t.Run("And", func(t *testing.T) {
 		assert.Equal(t, c.C.And(true, false), s.And(true, false))
 	})
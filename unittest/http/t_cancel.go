@@ -0,0 +1,39 @@
+package http
+
+import (
+	"context"
+	"github.com/go-kid/remote-ioc/defination"
+	"time"
+)
+
+type Waiter interface {
+	Wait(ctx context.Context, d time.Duration) bool
+}
+
+// WaiterImpl reports on interrupted whenever a Wait stops early.
+type WaiterImpl struct {
+	interrupted chan error
+}
+
+func (w *WaiterImpl) RemoteServiceId() string { return "Waiter" }
+
+func (w *WaiterImpl) Wait(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-ctx.Done():
+		w.interrupted <- ctx.Err()
+		return false
+	}
+}
+
+type WaiterInvoker struct {
+	Waiter
+	Invoke defination.Invoke
+}
+
+func (w *WaiterInvoker) RemoteServiceId() string { return "Waiter" }
+
+func (w *WaiterInvoker) RegisterInvoker(invoke defination.Invoke) {
+	w.Invoke = invoke
+}
@@ -0,0 +1,124 @@
+package http
+
+import (
+	"errors"
+	"github.com/go-kid/remote-ioc/defination"
+	"net"
+	"strings"
+	"time"
+)
+
+// Conformance echoes every kind of value the transmission layer supports.
+type Conformance interface {
+	EchoInt8(v int8) int8
+	EchoUint(v uint) uint
+	EchoUint8(v uint8) uint8
+	EchoFloat32(v float32) float32
+	EchoComplex64(v complex64) complex64
+	EchoComplex128(v complex128) complex128
+	EchoString(v string) string
+	EchoBytes(v []byte) []byte
+	EchoByteArray(v [4]byte) [4]byte
+	EchoNested(v [][]int) [][]int
+	EchoStringMap(v map[string]int) map[string]int
+	EchoIntMap(v map[int]string) map[int]string
+	EchoStructMap(v map[string]*Sub) map[string]*Sub
+	EchoLevelMap(v map[Level]Level) map[Level]Level
+	EchoIP(v net.IP) net.IP
+	EchoDuration(v time.Duration) time.Duration
+	EchoTime(v time.Time) time.Time
+	EchoTagged(v Tagged) Tagged
+	EchoTaggedPtr(v *Tagged) *Tagged
+	EchoTaggedSlice(v []Tagged) []Tagged
+}
+
+type Level int
+
+func (l Level) MarshalText() ([]byte, error) {
+	switch l {
+	case 0:
+		return []byte("low"), nil
+	case 1:
+		return []byte("high"), nil
+	}
+	return nil, errors.New("invalid level")
+}
+
+func (l *Level) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "low":
+		*l = 0
+	case "high":
+		*l = 1
+	default:
+		return errors.New("invalid level " + string(text))
+	}
+	return nil
+}
+
+type Embedded struct {
+	Name  string `json:"name"`
+	Inner int    `json:"inner"`
+}
+
+type EmbeddedPtr struct {
+	Deep string `json:"deep"`
+}
+
+type Tagged struct {
+	Name      string `json:"name"`
+	Omit      string `json:"omit,omitempty"`
+	Skip      string `json:"-"`
+	Dash      string `json:"-,"`
+	Quoted    int64  `json:"quoted,string"`
+	QuotedStr string `json:"quoted_str,string"`
+	Untagged  string
+	Embedded
+	*EmbeddedPtr
+	Anonymous struct {
+		X int `json:"x"`
+	} `json:"anonymous"`
+	Any   any    `json:"any"`
+	Level Level  `json:"level"`
+	Ptr   *int   `json:"ptr"`
+	Err   error  `json:"err"`
+	Bytes []byte `json:"bytes"`
+}
+
+type ConformanceImpl struct{}
+
+func (c *ConformanceImpl) RemoteServiceId() string { return "Conformance" }
+
+func (c *ConformanceImpl) EchoInt8(v int8) int8                            { return v }
+func (c *ConformanceImpl) EchoUint(v uint) uint                            { return v }
+func (c *ConformanceImpl) EchoUint8(v uint8) uint8                         { return v }
+func (c *ConformanceImpl) EchoFloat32(v float32) float32                   { return v }
+func (c *ConformanceImpl) EchoComplex64(v complex64) complex64             { return v }
+func (c *ConformanceImpl) EchoComplex128(v complex128) complex128          { return v }
+func (c *ConformanceImpl) EchoString(v string) string                      { return v }
+func (c *ConformanceImpl) EchoBytes(v []byte) []byte                       { return v }
+func (c *ConformanceImpl) EchoByteArray(v [4]byte) [4]byte                 { return v }
+func (c *ConformanceImpl) EchoNested(v [][]int) [][]int                    { return v }
+func (c *ConformanceImpl) EchoStringMap(v map[string]int) map[string]int   { return v }
+func (c *ConformanceImpl) EchoIntMap(v map[int]string) map[int]string      { return v }
+func (c *ConformanceImpl) EchoStructMap(v map[string]*Sub) map[string]*Sub { return v }
+func (c *ConformanceImpl) EchoLevelMap(v map[Level]Level) map[Level]Level  { return v }
+func (c *ConformanceImpl) EchoIP(v net.IP) net.IP                          { return v }
+func (c *ConformanceImpl) EchoDuration(v time.Duration) time.Duration      { return v }
+func (c *ConformanceImpl) EchoTime(v time.Time) time.Time                  { return v }
+func (c *ConformanceImpl) EchoTagged(v Tagged) Tagged                      { return v }
+func (c *ConformanceImpl) EchoTaggedPtr(v *Tagged) *Tagged                 { return v }
+func (c *ConformanceImpl) EchoTaggedSlice(v []Tagged) []Tagged             { return v }
+
+// ConformanceInvoker takes its method set from the embedded interface, the
+// methods are only used for their signatures and never called.
+type ConformanceInvoker struct {
+	Conformance
+	Invoke defination.Invoke
+}
+
+func (c *ConformanceInvoker) RemoteServiceId() string { return "Conformance" }
+
+func (c *ConformanceInvoker) RegisterInvoker(invoke defination.Invoke) {
+	c.Invoke = invoke
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"context"
+	"github.com/go-kid/remote-ioc/defination"
+	"github.com/go-kid/remote-ioc/http/transmission"
+)
+
+type Tenant struct {
+	Id     string `json:"id"`
+	Region string `json:"region"`
+}
+
+type traceKey struct{}
+
+var (
+	TenantKey  = transmission.NewContextKey[*Tenant]("tenant")
+	AttemptKey = transmission.NewContextKey[int]("attempt")
+	TraceIdKey = traceKey{}
+	unsentKey  = struct{ name string }{"unsent"}
+)
+
+func init() {
+	transmission.RegisterContextKey[string]("trace-id", TraceIdKey)
+}
+
+type ContextEcho interface {
+	Tenant(ctx context.Context) *Tenant
+	Attempt(ctx context.Context) (int, bool)
+	TraceId(ctx context.Context) string
+	Unsent(ctx context.Context) bool
+}
+
+type ContextEchoImpl struct{}
+
+func (c *ContextEchoImpl) RemoteServiceId() string { return "ContextEcho" }
+
+func (c *ContextEchoImpl) Tenant(ctx context.Context) *Tenant {
+	t, _ := TenantKey.Value(ctx)
+	return t
+}
+
+func (c *ContextEchoImpl) Attempt(ctx context.Context) (int, bool) {
+	return AttemptKey.Value(ctx)
+}
+
+func (c *ContextEchoImpl) TraceId(ctx context.Context) string {
+	id, _ := ctx.Value(TraceIdKey).(string)
+	return id
+}
+
+func (c *ContextEchoImpl) Unsent(ctx context.Context) bool {
+	return ctx.Value(unsentKey) != nil
+}
+
+type ContextEchoInvoker struct {
+	ContextEcho
+	Invoke defination.Invoke
+}
+
+func (c *ContextEchoInvoker) RemoteServiceId() string { return "ContextEcho" }
+
+func (c *ContextEchoInvoker) RegisterInvoker(invoke defination.Invoke) {
+	c.Invoke = invoke
+}
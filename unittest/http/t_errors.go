@@ -0,0 +1,126 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-kid/remote-ioc/defination"
+	"github.com/go-kid/remote-ioc/http/transmission"
+	"time"
+)
+
+var ErrNotFound = errors.New("not found")
+
+type QuotaError struct {
+	Resource string `json:"resource"`
+	Limit    int64  `json:"limit"`
+	Err      error  `json:"err"`
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("quota of %s exceeded (%d)", e.Resource, e.Limit)
+}
+
+func (e *QuotaError) Unwrap() error { return e.Err }
+
+func (e *QuotaError) ErrorCode() string { return "QUOTA_EXCEEDED" }
+
+func (e *QuotaError) ErrorDetails() map[string]any {
+	return map[string]any{"resource": e.Resource, "limit": e.Limit}
+}
+
+func init() {
+	transmission.RegisterError("errors.NotFound", ErrNotFound)
+	transmission.RegisterErrorType("errors.Quota", &QuotaError{})
+}
+
+type Failing interface {
+	Find(key string) (string, error)
+	Consume(resource string) error
+	Check(err error) bool
+	Count(items []int) int
+	Sleep(d time.Duration) bool
+	Explode(msg string) string
+}
+
+type FailingImpl struct{}
+
+func (f *FailingImpl) RemoteServiceId() string { return "Failing" }
+
+func (f *FailingImpl) Find(key string) (string, error) {
+	switch key {
+	case "sentinel":
+		return "", ErrNotFound
+	case "wrapped":
+		return "", fmt.Errorf("find %s: %w", key, ErrNotFound)
+	case "joined":
+		return "", errors.Join(ErrNotFound, &QuotaError{Resource: "disk"})
+	case "multi":
+		return "", fmt.Errorf("%w and %w", ErrNotFound, &QuotaError{Resource: "disk"})
+	case "plain":
+		return "", &unregisteredError{code: "PLAIN"}
+	}
+	return key, nil
+}
+
+func (f *FailingImpl) Consume(resource string) error {
+	return fmt.Errorf("consume: %w", &QuotaError{
+		Resource: resource,
+		Limit:    1 << 60,
+		Err:      fmt.Errorf("bucket empty: %w", ErrNotFound),
+	})
+}
+
+func (f *FailingImpl) Check(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+func (f *FailingImpl) Count(items []int) int { return len(items) }
+
+func (f *FailingImpl) Sleep(d time.Duration) bool {
+	time.Sleep(d)
+	return true
+}
+
+func (f *FailingImpl) Explode(msg string) string {
+	panic(msg)
+}
+
+type unregisteredError struct {
+	code string
+}
+
+func (e *unregisteredError) Error() string { return "unregistered " + e.code }
+
+func (e *unregisteredError) ErrorCode() string { return e.code }
+
+type FailingInvoker struct {
+	Failing
+	Invoke defination.Invoke
+}
+
+func (f *FailingInvoker) RemoteServiceId() string { return "Failing" }
+
+func (f *FailingInvoker) RegisterInvoker(invoke defination.Invoke) {
+	f.Invoke = invoke
+}
+
+// Mismatched disagrees with Failing on parameter types.
+type Mismatched interface {
+	Find(key int) (string, error)
+	Consume(resource string) error
+	Check(err error) bool
+	Count(items []string) int
+	Sleep(d time.Duration) bool
+	Explode(msg string) string
+}
+
+type MismatchedInvoker struct {
+	Mismatched
+	Invoke defination.Invoke
+}
+
+func (f *MismatchedInvoker) RemoteServiceId() string { return "Failing" }
+
+func (f *MismatchedInvoker) RegisterInvoker(invoke defination.Invoke) {
+	f.Invoke = invoke
+}
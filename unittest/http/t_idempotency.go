@@ -0,0 +1,37 @@
+package http
+
+import (
+	"context"
+	"github.com/go-kid/remote-ioc/defination"
+	"sync/atomic"
+	"time"
+)
+
+type Journal interface {
+	Append(ctx context.Context, amount int) int
+}
+
+// JournalImpl is not idempotent: every Append adds an entry, which takes
+// Delay, and returns the number of entries.
+type JournalImpl struct {
+	Delay   time.Duration
+	entries atomic.Int64
+}
+
+func (j *JournalImpl) RemoteServiceId() string { return "Journal" }
+
+func (j *JournalImpl) Append(ctx context.Context, amount int) int {
+	time.Sleep(j.Delay)
+	return int(j.entries.Add(1))
+}
+
+type JournalInvoker struct {
+	Journal
+	Invoke defination.Invoke
+}
+
+func (j *JournalInvoker) RemoteServiceId() string { return "Journal" }
+
+func (j *JournalInvoker) RegisterInvoker(invoke defination.Invoke) {
+	j.Invoke = invoke
+}
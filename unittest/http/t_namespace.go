@@ -0,0 +1,55 @@
+package http
+
+import (
+	"github.com/go-kid/remote-ioc/defination"
+)
+
+type Accounting interface {
+	Balance() string
+}
+
+type StagingAccountingImpl struct {
+	Server string
+}
+
+func (l *StagingAccountingImpl) RemoteServiceId() string        { return "Accounting" }
+func (l *StagingAccountingImpl) RemoteServiceNamespace() string { return "staging" }
+func (l *StagingAccountingImpl) RemoteServiceGroup() string     { return "payments" }
+func (l *StagingAccountingImpl) Balance() string                { return "staging@" + l.Server }
+
+type ProdAccountingImpl struct{}
+
+func (l *ProdAccountingImpl) RemoteServiceId() string        { return "Accounting" }
+func (l *ProdAccountingImpl) RemoteServiceNamespace() string { return "prod" }
+func (l *ProdAccountingImpl) RemoteServiceGroup() string     { return "payments" }
+func (l *ProdAccountingImpl) Balance() string                { return "prod" }
+
+type DefaultAccountingImpl struct{}
+
+func (l *DefaultAccountingImpl) RemoteServiceId() string { return "Accounting" }
+func (l *DefaultAccountingImpl) Balance() string         { return "default" }
+
+type AccountingInvoker struct {
+	Accounting
+	Invoke defination.Invoke
+}
+
+func (l *AccountingInvoker) RemoteServiceId() string { return "Accounting" }
+
+func (l *AccountingInvoker) RegisterInvoker(invoke defination.Invoke) {
+	l.Invoke = invoke
+}
+
+type StagingAccountingInvoker struct {
+	AccountingInvoker
+}
+
+func (l *StagingAccountingInvoker) RemoteServiceNamespace() string { return "staging" }
+func (l *StagingAccountingInvoker) RemoteServiceGroup() string     { return "payments" }
+
+type ProdAccountingInvoker struct {
+	AccountingInvoker
+}
+
+func (l *ProdAccountingInvoker) RemoteServiceNamespace() string { return "prod" }
+func (l *ProdAccountingInvoker) RemoteServiceGroup() string     { return "payments" }
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"fmt"
+	"github.com/go-kid/remote-ioc/defination"
+	"github.com/go-kid/remote-ioc/http/transmission"
+	"math"
+)
+
+type Shape interface {
+	Area() float64
+}
+
+type Circle struct {
+	R float64 `json:"r"`
+}
+
+func (c Circle) Area() float64 { return math.Pi * c.R * c.R }
+
+func (c Circle) String() string { return fmt.Sprintf("circle(%v)", c.R) }
+
+type Rect struct {
+	W float64 `json:"w"`
+	H float64 `json:"h"`
+}
+
+func (r *Rect) Area() float64 { return r.W * r.H }
+
+type Drawing struct {
+	Name   string           `json:"name"`
+	Shapes []Shape          `json:"shapes"`
+	ByName map[string]Shape `json:"by_name"`
+	Main   Shape            `json:"main"`
+	Extra  any              `json:"extra"`
+}
+
+func init() {
+	transmission.RegisterType("shape.Circle", Circle{})
+	transmission.RegisterType("shape.Rect", &Rect{})
+}
+
+type Polymorphic interface {
+	Largest(shapes []Shape) Shape
+	Describe(s fmt.Stringer) string
+	Echo(v any) any
+	EchoDrawing(d *Drawing) *Drawing
+}
+
+type PolymorphicImpl struct{}
+
+func (p *PolymorphicImpl) RemoteServiceId() string { return "Polymorphic" }
+
+func (p *PolymorphicImpl) Largest(shapes []Shape) Shape {
+	var largest Shape
+	for _, shape := range shapes {
+		if largest == nil || shape.Area() > largest.Area() {
+			largest = shape
+		}
+	}
+	return largest
+}
+
+func (p *PolymorphicImpl) Describe(s fmt.Stringer) string { return s.String() }
+
+func (p *PolymorphicImpl) Echo(v any) any { return v }
+
+func (p *PolymorphicImpl) EchoDrawing(d *Drawing) *Drawing { return d }
+
+type PolymorphicInvoker struct {
+	Polymorphic
+	Invoke defination.Invoke
+}
+
+func (p *PolymorphicInvoker) RemoteServiceId() string { return "Polymorphic" }
+
+func (p *PolymorphicInvoker) RegisterInvoker(invoke defination.Invoke) {
+	p.Invoke = invoke
+}
@@ -11,6 +11,9 @@ type ServerComponent interface {
 	SumI(base, add int) int
 	SumS(base, add string) string
 	SumF(base, add float64) float64
+	SumI64(base, add int64) int64
+	SumU64(base, add uint64) uint64
+	SumI8(base, add int8) int8
 	And(b1, b2 bool) bool
 	SumSliceI(base int, add []int) int
 	SumArrayI(base int, add [3]int) int
@@ -66,6 +69,18 @@ func (s *ServerComponentImpl) SumF(base, add float64) float64 {
 	return base + add
 }
 
+func (s *ServerComponentImpl) SumI64(base, add int64) int64 {
+	return base + add
+}
+
+func (s *ServerComponentImpl) SumU64(base, add uint64) uint64 {
+	return base + add
+}
+
+func (s *ServerComponentImpl) SumI8(base, add int8) int8 {
+	return base + add
+}
+
 func (s *ServerComponentImpl) And(b1, b2 bool) bool {
 	return b1 && b2
 }
@@ -0,0 +1,41 @@
+package http
+
+import (
+	"github.com/go-kid/remote-ioc/defination"
+)
+
+type Signup struct {
+	Name  string   `json:"name" validate:"required,max=10"`
+	Email string   `json:"email" validate:"required,email"`
+	Age   int      `json:"age" validate:"min=18"`
+	Pets  []*Pet   `json:"pets" validate:"max=2"`
+	Roles []string `json:"roles"`
+}
+
+type Pet struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type Accounts interface {
+	Register(s *Signup) string
+	Rename(id int, s Signup) bool
+}
+
+type AccountsImpl struct{}
+
+func (a *AccountsImpl) RemoteServiceId() string { return "Accounts" }
+
+func (a *AccountsImpl) Register(s *Signup) string { return "welcome " + s.Name }
+
+func (a *AccountsImpl) Rename(id int, s Signup) bool { return true }
+
+type AccountsInvoker struct {
+	Accounts
+	Invoke defination.Invoke
+}
+
+func (a *AccountsInvoker) RemoteServiceId() string { return "Accounts" }
+
+func (a *AccountsInvoker) RegisterInvoker(invoke defination.Invoke) {
+	a.Invoke = invoke
+}
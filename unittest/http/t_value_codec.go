@@ -0,0 +1,125 @@
+package http
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/go-kid/remote-ioc/defination"
+	"github.com/go-kid/remote-ioc/http/transmission"
+	"math/big"
+	"strings"
+)
+
+// Decimal keeps its state unexported, it only travels through its codec.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int
+}
+
+func NewDecimal(s string) Decimal {
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	unscaled, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		panic("invalid decimal " + s)
+	}
+	return Decimal{unscaled: unscaled, scale: len(fracPart)}
+}
+
+func (d Decimal) String() string {
+	if d.unscaled == nil {
+		return "0"
+	}
+	s := d.unscaled.String()
+	if d.scale == 0 {
+		return s
+	}
+	for len(s) <= d.scale {
+		s = "0" + s
+	}
+	return s[:len(s)-d.scale] + "." + s[len(s)-d.scale:]
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	for d.scale < o.scale {
+		d = Decimal{unscaled: new(big.Int).Mul(d.unscaled, big.NewInt(10)), scale: d.scale + 1}
+	}
+	for o.scale < d.scale {
+		o = Decimal{unscaled: new(big.Int).Mul(o.unscaled, big.NewInt(10)), scale: o.scale + 1}
+	}
+	return Decimal{unscaled: new(big.Int).Add(d.unscaled, o.unscaled), scale: d.scale}
+}
+
+type UUID [16]byte
+
+func (u UUID) String() string {
+	return hex.EncodeToString(u[:])
+}
+
+type Money struct {
+	Amount   Decimal `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+func init() {
+	transmission.RegisterValueCodec(func(d Decimal) (any, error) {
+		return d.String(), nil
+	}, func(val any) (Decimal, error) {
+		s, ok := val.(string)
+		if !ok {
+			return Decimal{}, errors.New("decimal is not a string")
+		}
+		return NewDecimal(s), nil
+	})
+	transmission.RegisterValueCodec(func(u UUID) (any, error) {
+		return u.String(), nil
+	}, func(val any) (UUID, error) {
+		var u UUID
+		s, ok := val.(string)
+		if !ok {
+			return u, errors.New("uuid is not a string")
+		}
+		b, err := hex.DecodeString(s)
+		if err != nil || len(b) != len(u) {
+			return u, fmt.Errorf("invalid uuid %q", s)
+		}
+		copy(u[:], b)
+		return u, nil
+	})
+}
+
+type Ledger interface {
+	Total(items []Money) Money
+	Echo(v map[string]*Money) map[string]*Money
+	Next(id UUID) UUID
+}
+
+type LedgerImpl struct{}
+
+func (l *LedgerImpl) RemoteServiceId() string { return "Ledger" }
+
+func (l *LedgerImpl) Total(items []Money) Money {
+	var total = Money{Amount: NewDecimal("0")}
+	for _, item := range items {
+		total.Amount = total.Amount.Add(item.Amount)
+		total.Currency = item.Currency
+	}
+	return total
+}
+
+func (l *LedgerImpl) Echo(v map[string]*Money) map[string]*Money { return v }
+
+func (l *LedgerImpl) Next(id UUID) UUID {
+	id[15]++
+	return id
+}
+
+type LedgerInvoker struct {
+	Ledger
+	Invoke defination.Invoke
+}
+
+func (l *LedgerInvoker) RemoteServiceId() string { return "Ledger" }
+
+func (l *LedgerInvoker) RegisterInvoker(invoke defination.Invoke) {
+	l.Invoke = invoke
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"github.com/go-kid/remote-ioc/defination"
+)
+
+type Greeter interface {
+	Hello(name string) string
+}
+
+type GreeterV2 interface {
+	Greeter
+	Bye(name string) string
+}
+
+// GreeterImpl exports the Greeter service in the version it is created with.
+type GreeterImpl struct {
+	Version string
+	Server  string
+}
+
+func (g *GreeterImpl) RemoteServiceId() string { return "Greeter" }
+
+func (g *GreeterImpl) RemoteServiceVersion() string { return g.Version }
+
+func (g *GreeterImpl) Hello(name string) string {
+	return "hello " + name + " from " + g.Version + "@" + g.Server
+}
+
+type GreeterV2Impl struct {
+	GreeterImpl
+}
+
+func (g *GreeterV2Impl) Bye(name string) string {
+	return "bye " + name
+}
+
+type LegacyGreeterImpl struct{}
+
+func (g *LegacyGreeterImpl) RemoteServiceId() string { return "Greeter" }
+
+func (g *LegacyGreeterImpl) Hello(name string) string { return "hi " + name }
+
+type GreeterInvoker struct {
+	Greeter
+	Constraint string
+	Invoke     defination.Invoke
+}
+
+func (g *GreeterInvoker) RemoteServiceId() string { return "Greeter" }
+
+func (g *GreeterInvoker) RemoteVersionConstraint() string { return g.Constraint }
+
+func (g *GreeterInvoker) RegisterInvoker(invoke defination.Invoke) {
+	g.Invoke = invoke
+}
+
+type GreeterV2Invoker struct {
+	GreeterV2
+	Invoke defination.Invoke
+}
+
+func (g *GreeterV2Invoker) RemoteServiceId() string { return "Greeter" }
+
+func (g *GreeterV2Invoker) RemoteVersionConstraint() string { return "^2" }
+
+func (g *GreeterV2Invoker) RegisterInvoker(invoke defination.Invoke) {
+	g.Invoke = invoke
+}
+
+type LegacyGreeterInvoker struct {
+	Greeter
+	Invoke defination.Invoke
+}
+
+func (g *LegacyGreeterInvoker) RemoteServiceId() string { return "Greeter" }
+
+func (g *LegacyGreeterInvoker) RegisterInvoker(invoke defination.Invoke) {
+	g.Invoke = invoke
+}
+
+// GreeterPatchInvoker is a second GreeterInvoker in the same container.
+type GreeterPatchInvoker struct {
+	GreeterInvoker
+}
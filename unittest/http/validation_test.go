@@ -0,0 +1,69 @@
+package http
+
+import (
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestArgumentValidation(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&AccountsImpl{}),
+		server.Handle(server.Config{
+			Addr: ":8907",
+		}),
+	)
+	var valid = Signup{Name: "kid", Email: "kid@example.com", Age: 30}
+	for _, preflight := range []bool{false, true} {
+		var c = &AccountsInvoker{}
+		ioc.RunTest(t,
+			app.SetComponents(c),
+			client.Remote(client.Config{
+				Servers:  []client.ServerConfig{{Addr: "http://localhost:8907"}},
+				Validate: lo.ToPtr(preflight),
+			}),
+		)
+		var status = http.StatusBadRequest
+		if preflight {
+			status = 0
+		}
+		t.Run("Valid", func(t *testing.T) {
+			result, err := c.Invoke("Register", &valid)
+			assert.NoError(t, err)
+			assert.Equal(t, "welcome kid", result[0])
+		})
+		t.Run("Field", func(t *testing.T) {
+			s := valid
+			s.Email = "not an email"
+			_, err := c.Invoke("Register", &s)
+			assertViolation(t, err, status, 1, "email", "email")
+		})
+		t.Run("Nested", func(t *testing.T) {
+			s := valid
+			s.Pets = []*Pet{{Name: "rex"}, {}}
+			_, err := c.Invoke("Rename", 7, s)
+			assertViolation(t, err, status, 2, "pets[1].name", "required")
+		})
+	}
+}
+
+func assertViolation(t *testing.T, err error, status, order int, field, rule string) {
+	var invokeErr *client.InvokeError
+	if !assert.ErrorAs(t, err, &invokeErr) {
+		return
+	}
+	assert.ErrorIs(t, err, client.ErrValidation)
+	assert.Equal(t, status, invokeErr.StatusCode)
+	var validateErr *dto.ValidateError
+	if assert.ErrorAs(t, err, &validateErr) {
+		assert.Equal(t, order, validateErr.ParamOrder)
+		assert.Equal(t, field, validateErr.Field)
+		assert.Equal(t, rule, validateErr.Rule)
+	}
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/codec"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValueCodec(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(&LedgerImpl{}),
+		server.Handle(server.Config{
+			Addr: ":8900",
+		}),
+	)
+	for _, name := range codec.Names() {
+		t.Run(name, func(t *testing.T) {
+			var c = &LedgerInvoker{}
+			ioc.RunTest(t,
+				app.SetComponents(c),
+				client.Remote(client.Config{
+					Servers: []client.ServerConfig{{Addr: "http://localhost:8900"}},
+					Codecs:  []string{name},
+				}),
+			)
+			t.Run("Total", func(t *testing.T) {
+				results, err := c.Invoke("Total", []Money{
+					{Amount: NewDecimal("12345678901234567890.05"), Currency: "EUR"},
+					{Amount: NewDecimal("0.951"), Currency: "EUR"},
+				})
+				assert.NoError(t, err)
+				total := results[0].(Money)
+				assert.Equal(t, "12345678901234567891.001", total.Amount.String())
+				assert.Equal(t, "EUR", total.Currency)
+			})
+			t.Run("Nested", func(t *testing.T) {
+				in := map[string]*Money{
+					"a": {Amount: NewDecimal("-1.5"), Currency: "USD"},
+					"b": nil,
+				}
+				results, err := c.Invoke("Echo", in)
+				assert.NoError(t, err)
+				out := results[0].(map[string]*Money)
+				assert.Equal(t, "-1.5", out["a"].Amount.String())
+				assert.Nil(t, out["b"])
+			})
+			t.Run("Next", func(t *testing.T) {
+				id := UUID{15: 1}
+				results, err := c.Invoke("Next", id)
+				assert.NoError(t, err)
+				assert.Equal(t, UUID{15: 2}, results[0])
+			})
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"github.com/go-kid/ioc"
+	"github.com/go-kid/ioc/app"
+	"github.com/go-kid/ioc/registry"
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestServiceVersions(t *testing.T) {
+	ioc.RunTest(t,
+		app.SetComponents(
+			&LegacyGreeterImpl{},
+			&GreeterImpl{Version: "1.0.0", Server: "a"},
+			&GreeterV2Impl{GreeterImpl{Version: "2.0.0", Server: "a"}},
+		),
+		server.Handle(server.Config{Addr: ":8912"}),
+	)
+	ioc.RunTest(t,
+		app.SetComponents(
+			&GreeterImpl{Version: "1.4.0", Server: "b"},
+			&GreeterV2Impl{GreeterImpl{Version: "2.0.0", Server: "b"}},
+		),
+		server.Handle(server.Config{Addr: ":8913"}),
+	)
+	var servers = []client.ServerConfig{{Addr: "http://localhost:8912"}, {Addr: "http://localhost:8913"}}
+
+	t.Run("Meta", func(t *testing.T) {
+		var meta []*dto.ServerInfo
+		response, err := resty.New().R().SetResult(&meta).Get("http://localhost:8912" + constant.RouteMeta)
+		assert.NoError(t, err)
+		assert.Equal(t, "json,msgpack,cbor", response.Header().Get(constant.HeaderCodecs))
+		versions := map[string][]string{}
+		for _, info := range meta {
+			versions[info.Version] = info.Methods
+		}
+		assert.Equal(t, map[string][]string{
+			"":      {"Hello", "RemoteServiceId"},
+			"1.0.0": {"Hello", "RemoteServiceId"},
+			"2.0.0": {"Bye", "Hello", "RemoteServiceId"},
+		}, versions)
+	})
+	t.Run("Constraint", func(t *testing.T) {
+		var (
+			v1     = &GreeterInvoker{Constraint: "^1"}
+			v1_0   = &GreeterPatchInvoker{GreeterInvoker{Constraint: "~1.0"}}
+			v2     = &GreeterV2Invoker{}
+			legacy = &LegacyGreeterInvoker{}
+		)
+		ioc.RunTest(t,
+			app.SetComponents(v1, v1_0, v2, legacy),
+			client.Remote(client.Config{Servers: servers}),
+		)
+		result, err := v1.Invoke("Hello", "kid")
+		assert.NoError(t, err)
+		assert.Equal(t, "hello kid from 1.4.0@b", result[0])
+
+		result, err = v1_0.Invoke("Hello", "kid")
+		assert.NoError(t, err)
+		assert.Equal(t, "hello kid from 1.0.0@a", result[0])
+
+		// both servers export 2.0.0 and share its calls
+		var seen = map[string]bool{}
+		for i := 0; i < 4; i++ {
+			result, err = v2.Invoke("Hello", "kid")
+			assert.NoError(t, err)
+			seen[result[0].(string)] = true
+		}
+		assert.Equal(t, map[string]bool{"hello kid from 2.0.0@a": true, "hello kid from 2.0.0@b": true}, seen)
+		result, err = v2.Invoke("Bye", "kid")
+		assert.NoError(t, err)
+		assert.Equal(t, "bye kid", result[0])
+
+		result, err = legacy.Invoke("Hello", "kid")
+		assert.NoError(t, err)
+		assert.Equal(t, "hi kid", result[0])
+	})
+	t.Run("Unmatched", func(t *testing.T) {
+		_, err := ioc.Run(
+			app.SetRegistry(registry.NewRegistry()),
+			app.SetComponents(&GreeterInvoker{Constraint: ">=3"}),
+			client.Remote(client.Config{Servers: servers}),
+		)
+		assert.ErrorContains(t, err, `no exported version matches ">=3"`)
+	})
+	t.Run("Duplicate", func(t *testing.T) {
+		_, err := ioc.Run(
+			app.SetRegistry(registry.NewRegistry()),
+			app.SetComponents(&GreeterImpl{Version: "1.0.0"}, &GreeterV2Impl{GreeterImpl{Version: "1.0.0"}}),
+			server.Handle(server.Config{Addr: ":8914"}),
+		)
+		assert.ErrorContains(t, err, "exported twice")
+	})
+}
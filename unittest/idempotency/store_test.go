@@ -0,0 +1,98 @@
+package idempotency
+
+import (
+	"context"
+	"github.com/go-kid/remote-ioc/http/idempotency"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStores(t *testing.T) {
+	dir := t.TempDir()
+	file, err := idempotency.File(dir)
+	assert.NoError(t, err)
+	ctx := context.Background()
+	for name, store := range map[string]idempotency.Store{"Memory": idempotency.Memory(), "File": file} {
+		store := store
+		t.Run(name, func(t *testing.T) {
+			response := &idempotency.Response{Status: 200, ContentType: "application/json", Body: []byte(`{"params":[]}`)}
+			_, ok, err := store.Get(ctx, "Journal.Append/a")
+			assert.NoError(t, err)
+			assert.False(t, ok)
+
+			assert.NoError(t, store.Put(ctx, "Journal.Append/a", response, time.Minute))
+			got, ok, err := store.Get(ctx, "Journal.Append/a")
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, response, got)
+
+			assert.NoError(t, store.Put(ctx, "Journal.Append/b", response, 50*time.Millisecond))
+			time.Sleep(100 * time.Millisecond)
+			_, ok, err = store.Get(ctx, "Journal.Append/b")
+			assert.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+
+	// responses in files survive the store
+	reopened, err := idempotency.File(dir)
+	assert.NoError(t, err)
+	got, ok, err := reopened.Get(ctx, "Journal.Append/a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte(`{"params":[]}`), got.Body)
+}
+
+func TestMemoryStoreBound(t *testing.T) {
+	store := idempotency.BoundedMemory(2)
+	ctx := context.Background()
+	response := &idempotency.Response{Status: 200}
+	for _, key := range []string{"a", "b", "a", "c"} {
+		assert.NoError(t, store.Put(ctx, key, response, time.Minute))
+	}
+	for key, kept := range map[string]bool{"a": true, "b": false, "c": true} {
+		_, ok, err := store.Get(ctx, key)
+		assert.NoError(t, err)
+		assert.Equal(t, kept, ok, key)
+	}
+}
+
+func TestFileStoreCorruptEntry(t *testing.T) {
+	dir := t.TempDir()
+	file, err := idempotency.File(dir)
+	assert.NoError(t, err)
+	ctx := context.Background()
+	response := &idempotency.Response{Status: 200, Body: []byte(`{"params":[]}`)}
+	assert.NoError(t, file.Put(ctx, "Journal.Append/a", response, time.Minute))
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	// truncated by a crash
+	path := filepath.Join(dir, files[0].Name())
+	assert.NoError(t, os.Truncate(path, 10))
+
+	_, ok, err := file.Get(ctx, "Journal.Append/a")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.NoFileExists(t, path)
+}
+
+func TestFileStoreSweepsTemporaryFiles(t *testing.T) {
+	dir := t.TempDir()
+	var (
+		stale = filepath.Join(dir, "a.json.1.tmp")
+		fresh = filepath.Join(dir, "b.json.2.tmp")
+	)
+	assert.NoError(t, os.WriteFile(stale, []byte(`{"resp`), 0o644))
+	assert.NoError(t, os.WriteFile(fresh, []byte(`{"resp`), 0o644))
+	old := time.Now().Add(-time.Hour)
+	assert.NoError(t, os.Chtimes(stale, old, old))
+
+	_, err := idempotency.File(dir)
+	assert.NoError(t, err)
+	assert.NoFileExists(t, stale)
+	assert.FileExists(t, fresh)
+}
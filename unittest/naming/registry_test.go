@@ -0,0 +1,157 @@
+package naming
+
+import (
+	"context"
+	"github.com/go-kid/remote-ioc/http/discovery"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/naming"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newRegistry(t *testing.T, c naming.Config) *naming.Registry {
+	r, err := naming.New(c)
+	assert.NoError(t, err)
+	t.Cleanup(r.Close)
+	return r
+}
+
+func TestLeases(t *testing.T) {
+	ctx := context.Background()
+	r := newRegistry(t, naming.Config{Sweep: 20 * time.Millisecond})
+
+	_, err := r.Register(ctx, &naming.Registration{Instance: &discovery.Instance{Addr: "http://a"}})
+	assert.Error(t, err)
+
+	lease, err := r.Register(ctx, &naming.Registration{
+		Instance: &discovery.Instance{Addr: "http://a", Zone: "eu"},
+		Services: []dto.ServiceKey{{ServiceId: "Orders"}, {ServiceId: "Billing"}},
+		TTL:      1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, lease.TTL)
+	state, err := r.Instances(ctx, "Billing", 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []*discovery.Instance{{Addr: "http://a", Zone: "eu"}}, state.Instances)
+
+	time.Sleep(600 * time.Millisecond)
+	renewed, err := r.KeepAlive(ctx, lease.Id)
+	assert.NoError(t, err)
+	assert.True(t, renewed.ExpiresAt.After(lease.ExpiresAt))
+	time.Sleep(600 * time.Millisecond)
+	assert.Len(t, r.Leases(), 1)
+
+	assert.Eventually(t, func() bool {
+		return len(r.Leases()) == 0
+	}, 2*time.Second, 20*time.Millisecond)
+	_, err = r.KeepAlive(ctx, lease.Id)
+	assert.ErrorIs(t, err, naming.ErrLeaseNotFound)
+	assert.ErrorIs(t, r.Deregister(ctx, lease.Id), naming.ErrLeaseNotFound)
+}
+
+func TestLongPolling(t *testing.T) {
+	ctx := context.Background()
+	r := newRegistry(t, naming.Config{})
+	state, err := r.Instances(ctx, "Orders", 0, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, state.Instances)
+
+	start := time.Now()
+	timedOut, err := r.Instances(ctx, "Orders", state.Index, 50*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, state.Index, timedOut.Index)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = r.Register(ctx, &naming.Registration{Instance: &discovery.Instance{Addr: "http://b"}, Services: []dto.ServiceKey{{ServiceId: "Billing"}}})
+		time.Sleep(50 * time.Millisecond)
+		_, _ = r.Register(ctx, &naming.Registration{Instance: &discovery.Instance{Addr: "http://a"}, Services: []dto.ServiceKey{{ServiceId: "Orders"}}})
+	}()
+	next, err := r.Instances(ctx, "Orders", state.Index, time.Second)
+	assert.NoError(t, err)
+	assert.Greater(t, next.Index, state.Index)
+	assert.Equal(t, []*discovery.Instance{{Addr: "http://a"}}, next.Instances)
+
+	past, err := r.Instances(ctx, "Orders", next.Index+10, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, next.Index, past.Index)
+}
+
+func TestPersistence(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "registry.json")
+	r := newRegistry(t, naming.Config{File: file})
+	kept, err := r.Register(ctx, &naming.Registration{Instance: &discovery.Instance{Addr: "http://a"}, Services: []dto.ServiceKey{{ServiceId: "Orders"}}})
+	assert.NoError(t, err)
+	gone, err := r.Register(ctx, &naming.Registration{Instance: &discovery.Instance{Addr: "http://b"}, Services: []dto.ServiceKey{{ServiceId: "Orders"}}})
+	assert.NoError(t, err)
+	assert.NoError(t, r.Deregister(ctx, gone.Id))
+	r.Close()
+
+	restored := newRegistry(t, naming.Config{File: file})
+	leases := restored.Leases()
+	assert.Len(t, leases, 1)
+	assert.Equal(t, kept.Id, leases[0].Id)
+	_, err = restored.KeepAlive(ctx, kept.Id)
+	assert.NoError(t, err)
+	state, err := restored.Instances(ctx, "Orders", 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), state.Index)
+}
+
+func TestMaxTTL(t *testing.T) {
+	r := newRegistry(t, naming.Config{MaxTTL: 10 * time.Second})
+	lease, err := r.Register(context.Background(), &naming.Registration{
+		Instance: &discovery.Instance{Addr: "http://a"},
+		Services: []dto.ServiceKey{{ServiceId: "Orders"}},
+		TTL:      3600,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 10, lease.TTL)
+	assert.WithinDuration(t, time.Now().Add(10*time.Second), lease.ExpiresAt, time.Second)
+}
+
+func TestClient(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := newRegistry(t, naming.Config{})
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+	c := naming.NewClient(naming.ClientConfig{Addr: srv.URL, Wait: time.Second})
+
+	ch, err := c.Watch(ctx, "Orders")
+	assert.NoError(t, err)
+	assert.Empty(t, <-ch)
+
+	lease, err := c.Register(ctx, &naming.Registration{
+		Instance: &discovery.Instance{Addr: "http://a", Weight: 2, Metadata: map[string]string{"build": "42"}},
+		Services: []dto.ServiceKey{{ServiceId: "Orders"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int(naming.DefaultTTL/time.Second), lease.TTL)
+	select {
+	case instances := <-ch:
+		assert.Equal(t, []*discovery.Instance{{Addr: "http://a", Weight: 2, Metadata: map[string]string{"build": "42"}}}, instances)
+	case <-time.After(time.Second):
+		t.Fatal("no update")
+	}
+
+	_, err = c.KeepAlive(ctx, lease.Id)
+	assert.NoError(t, err)
+	assert.NoError(t, c.Deregister(ctx, lease.Id))
+	_, err = c.KeepAlive(ctx, lease.Id)
+	assert.ErrorIs(t, err, naming.ErrLeaseNotFound)
+	select {
+	case instances := <-ch:
+		assert.Empty(t, instances)
+	case <-time.After(time.Second):
+		t.Fatal("no update")
+	}
+
+	_, err = c.Register(ctx, &naming.Registration{Instance: &discovery.Instance{Addr: "http://a"}})
+	assert.Error(t, err)
+}
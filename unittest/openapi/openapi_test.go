@@ -0,0 +1,115 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-kid/remote-ioc/http/constant"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/openapi"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type Node struct {
+	Name     string  `json:"name" validate:"required,max=20"`
+	Weight   int     `json:"weight,string"`
+	Children []*Node `json:"children,omitempty"`
+	Hidden   string  `json:"-"`
+}
+
+type Shape interface {
+	Area() float64
+}
+
+type Service struct{}
+
+func (s *Service) Tree(ctx context.Context, root *Node, depth uint8) (*Node, error) {
+	return root, nil
+}
+
+func (s *Service) Draw(shape Shape, at time.Time, tags ...string) []byte {
+	return nil
+}
+
+func build() *openapi.Document {
+	t := reflect.TypeOf(&Service{})
+	var methods []openapi.Method
+	for _, name := range []string{"Tree", "Draw"} {
+		m, _ := t.MethodByName(name)
+		methods = append(methods, openapi.Method{Service: dto.ServiceKey{ServiceId: "Svc"}, Name: name, Type: m.Type})
+	}
+	return openapi.Build(&openapi.Info{Title: "test", Version: "1"}, []string{"application/json"}, methods)
+}
+
+func params(t *testing.T, doc *openapi.Document, method string) []*openapi.Schema {
+	op := doc.Paths["/component/Svc/methods/"+method].Post
+	if !assert.NotNil(t, op) {
+		t.FailNow()
+	}
+	return op.RequestBody.Content["application/json"].Schema.Properties["params"].Items.OneOf
+}
+
+func TestBuild(t *testing.T) {
+	doc := build()
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+
+	tree := params(t, doc, "Tree")
+	if assert.Len(t, tree, 3) {
+		assert.Equal(t, []any{"context.Context"}, tree[0].Properties["kind"].Enum)
+		assert.Equal(t, "object", tree[0].Properties["value"].Type)
+		assert.Equal(t, []any{2}, tree[1].Properties["order"].Enum)
+		assert.Equal(t, []any{"ptr"}, tree[1].Properties["kind"].Enum)
+		assert.Equal(t, "#/components/schemas/openapi.Node", tree[1].Properties["value"].AllOf[0].Ref)
+		assert.Equal(t, "int32", tree[2].Properties["value"].Format)
+		assert.Equal(t, 0.0, *tree[2].Properties["value"].Minimum)
+	}
+	op := doc.Paths["/component/Svc/methods/Tree"].Post
+	assert.Equal(t, "Tree(context.Context, *openapi.Node, uint8) (*openapi.Node, error)", op.Description[len("Go signature: "):])
+	results := op.Responses["200"].Content["application/json"].Schema.Properties["params"].Items.OneOf
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, []any{"error"}, results[1].Properties["kind"].Enum)
+		assert.Equal(t, "#/components/schemas/dto.ErrorEnvelope", results[1].Properties["value"].AllOf[0].Ref)
+	}
+	assert.Contains(t, op.Responses, "400")
+	assert.Contains(t, op.Responses, "500")
+	assert.Contains(t, op.Responses["401"].Headers, "WWW-Authenticate")
+	assert.Contains(t, op.Responses["429"].Headers, "Retry-After")
+	assert.Contains(t, op.Responses["429"].Headers, constant.HeaderErrorKind)
+	assert.Contains(t, op.Responses["503"].Headers, constant.HeaderErrorKind)
+	assert.Equal(t, []any{constant.ErrorKindOverload, constant.ErrorKindUnavailable}, op.Responses["503"].Headers[constant.HeaderErrorKind].Schema.Enum)
+
+	draw := params(t, doc, "Draw")
+	if assert.Len(t, draw, 3) {
+		assert.Equal(t, []any{"openapi.Shape"}, draw[0].Properties["kind"].Enum)
+		assert.Contains(t, draw[0].Properties, "type")
+		assert.Equal(t, "date-time", draw[1].Properties["value"].Format)
+		assert.Equal(t, "array", draw[2].Properties["value"].Type)
+	}
+}
+
+func TestStructSchema(t *testing.T) {
+	node := build().Components.Schemas["openapi.Node"]
+	if !assert.NotNil(t, node) {
+		return
+	}
+	assert.Equal(t, []string{"name"}, node.Required)
+	assert.Equal(t, 20, *node.Properties["name"].MaxLength)
+	assert.Equal(t, "string", node.Properties["weight"].Type)
+	assert.Equal(t, "#/components/schemas/openapi.Node", node.Properties["children"].Items.AllOf[0].Ref)
+	assert.NotContains(t, node.Properties, "Hidden")
+
+	param := build().Components.Schemas["dto.Param"]
+	if assert.NotNil(t, param) {
+		assert.NotEmpty(t, param.Properties["kind"].Description)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	data, err := json.Marshal(build())
+	assert.NoError(t, err)
+	var doc map[string]any
+	assert.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "3.0.3", doc["openapi"])
+}
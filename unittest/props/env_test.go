@@ -0,0 +1,97 @@
+package props
+
+import (
+	"github.com/go-kid/remote-ioc/http/client"
+	"github.com/go-kid/remote-ioc/http/props"
+	"github.com/go-kid/remote-ioc/http/server"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type nested struct {
+	Token string `mapstructure:"token"`
+}
+
+type properties struct {
+	Addr     string            `mapstructure:"addr"`
+	Codecs   []string          `mapstructure:"codecs"`
+	Debug    bool              `mapstructure:"debug"`
+	Weight   int               `mapstructure:"weight"`
+	Rate     float64           `mapstructure:"rate"`
+	Port     uint16            `mapstructure:"port"`
+	Timeout  time.Duration     `mapstructure:"timeout"`
+	Metadata map[string]string `mapstructure:"metadata"`
+	Auth     nested            `mapstructure:"auth"`
+	Optional *nested           `mapstructure:"optional"`
+	Untagged string
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "REMOTE_IOC_SERVER_ROUTE_PREFIX", props.EnvName("remote-ioc.server.route-prefix"))
+}
+
+func TestEnv(t *testing.T) {
+	t.Setenv("APP_ADDR", ":9000")
+	t.Setenv("APP_CODECS", "json, cbor")
+	t.Setenv("APP_DEBUG", "true")
+	t.Setenv("APP_WEIGHT", "3")
+	t.Setenv("APP_RATE", "2.5")
+	t.Setenv("APP_PORT", "8080")
+	t.Setenv("APP_TIMEOUT", "1.5s")
+	t.Setenv("APP_METADATA", "zone=eu, rack=r1")
+	t.Setenv("APP_AUTH_TOKEN", "secret")
+	t.Setenv("APP_UNTAGGED", "ignored")
+	p := &properties{Addr: ":8080", Weight: 1}
+	assert.NoError(t, props.Env("app", p))
+	assert.Equal(t, &properties{
+		Addr:     ":9000",
+		Codecs:   []string{"json", "cbor"},
+		Debug:    true,
+		Weight:   3,
+		Rate:     2.5,
+		Port:     8080,
+		Timeout:  1500 * time.Millisecond,
+		Metadata: map[string]string{"zone": "eu", "rack": "r1"},
+		Auth:     nested{Token: "secret"},
+	}, p)
+
+	t.Setenv("APP_OPTIONAL_TOKEN", "set")
+	assert.NoError(t, props.Env("app", p))
+	assert.Equal(t, &nested{Token: "set"}, p.Optional)
+}
+
+func TestEnvServerProperties(t *testing.T) {
+	t.Setenv("REMOTE_IOC_SERVER_RATE_LIMITS_GLOBAL_RATE", "5")
+	t.Setenv("REMOTE_IOC_SERVER_RATE_LIMITS_GLOBAL_BURST", "10")
+	p := &server.Properties{}
+	assert.NoError(t, props.Env(server.PropertiesKey, p))
+	assert.Equal(t, server.RateLimit{Rate: 5, Burst: 10}, p.RateLimits.Global)
+}
+
+func TestEnvClientProperties(t *testing.T) {
+	t.Setenv("REMOTE_IOC_CLIENT_CONCURRENCY_MAX", "64")
+	t.Setenv("REMOTE_IOC_CLIENT_CONCURRENCY_BACKOFF", "0.5")
+	p := &client.Properties{}
+	assert.NoError(t, props.Env(client.PropertiesKey, p))
+	assert.Equal(t, 64, p.Concurrency.Max)
+	assert.Equal(t, 0.5, p.Concurrency.Backoff)
+}
+
+func TestEnvErrors(t *testing.T) {
+	for name, value := range map[string]string{
+		"APP_DEBUG":    "maybe",
+		"APP_WEIGHT":   "heavy",
+		"APP_RATE":     "fast",
+		"APP_PORT":     "-1",
+		"APP_TIMEOUT":  "10",
+		"APP_METADATA": "zone",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			err := props.Env("app", &properties{})
+			assert.ErrorContains(t, err, name)
+			assert.ErrorContains(t, err, value)
+		})
+	}
+}
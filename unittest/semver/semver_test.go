@@ -0,0 +1,73 @@
+package semver
+
+import (
+	"github.com/go-kid/remote-ioc/http/semver"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	v, err := semver.Parse("v1.2")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.0", v.String())
+	v, err = semver.Parse("1.2.3-rc.1+build.5")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3-rc.1", v.String())
+	for _, invalid := range []string{"", "1.2.3.4", "a.b", "1.2-rc", "1.x"} {
+		_, err = semver.Parse(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0"}
+	for i := 1; i < len(ordered); i++ {
+		a, _ := semver.Parse(ordered[i-1])
+		b, _ := semver.Parse(ordered[i])
+		assert.Equal(t, -1, a.Compare(b), "%s < %s", a, b)
+		assert.Equal(t, 1, b.Compare(a), "%s > %s", b, a)
+	}
+}
+
+func TestConstraint(t *testing.T) {
+	var tests = []struct {
+		constraint string
+		match      []string
+		miss       []string
+	}{
+		{"", []string{"0.0.1", "3.4.5"}, []string{"1.0.0-rc.1"}},
+		{"1.2.3", []string{"1.2.3"}, []string{"1.2.4"}},
+		{"^1.2.3", []string{"1.2.3", "1.9.0"}, []string{"1.2.2", "2.0.0", "1.3.0-rc.1"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.3.0"}},
+		{"~1", []string{"1.0.0", "1.9.9"}, []string{"2.0.0"}},
+		{"1.2.x", []string{"1.2.0", "1.2.7"}, []string{"1.3.0"}},
+		{"1", []string{"1.0.0", "1.5.0"}, []string{"2.0.0"}},
+		{">=1.0 <2", []string{"1.0.0", "1.9.9"}, []string{"0.9.0", "2.0.0"}},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{"<=1.2", []string{"1.2.9"}, []string{"1.3.0"}},
+		{">=1.0.0, !=1.1.0", []string{"1.0.0", "1.2.0"}, []string{"1.1.0"}},
+		{"^1 || ^3", []string{"1.4.0", "3.0.0"}, []string{"2.0.0"}},
+		{">=1.2.3-beta.2 <2", []string{"1.2.3-beta.3", "1.2.3", "1.5.0"}, []string{"1.2.3-beta.1", "1.4.0-beta.1"}},
+		{"*", []string{"0.0.0", "9.9.9"}, nil},
+	}
+	for _, tt := range tests {
+		c, err := semver.ParseConstraint(tt.constraint)
+		if !assert.NoError(t, err, tt.constraint) {
+			continue
+		}
+		for _, s := range tt.match {
+			v, _ := semver.Parse(s)
+			assert.True(t, c.Check(v), "%q should match %s", tt.constraint, s)
+		}
+		for _, s := range tt.miss {
+			v, _ := semver.Parse(s)
+			assert.False(t, c.Check(v), "%q should not match %s", tt.constraint, s)
+		}
+	}
+	for _, invalid := range []string{">=a", "!=1.2", "1.2.3.4"} {
+		_, err := semver.ParseConstraint(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
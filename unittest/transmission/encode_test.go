@@ -0,0 +1,41 @@
+package transmission
+
+import (
+	"github.com/go-kid/remote-ioc/http/transmission"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+type Inner struct {
+	A string `json:"a"`
+	B string
+}
+
+type Outer struct {
+	Inner
+	A       string `json:"a"`
+	Omit    string `json:"omit,omitempty"`
+	Skip    string `json:"-"`
+	Dash    string `json:"-,"`
+	Quoted  bool   `json:",string"`
+	private string
+}
+
+func TestEncryptStructTags(t *testing.T) {
+	p, err := transmission.EncryptParam(1, reflect.TypeOf(Outer{}), Outer{
+		Inner:   Inner{A: "inner", B: "b"},
+		A:       "outer",
+		Skip:    "skip",
+		Dash:    "dash",
+		Quoted:  true,
+		private: "private",
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"a":      "outer",
+		"B":      "b",
+		"-":      "dash",
+		"Quoted": "true",
+	}, p.Value)
+}
@@ -0,0 +1,116 @@
+package transmission
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/transmission"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecryptNumber(t *testing.T) {
+	var tests = []struct {
+		name  string
+		value any
+		want  any
+	}{
+		{"int64 max", json.Number("9223372036854775807"), int64(math.MaxInt64)},
+		{"int64 min", json.Number("-9223372036854775808"), int64(math.MinInt64)},
+		{"uint64 max", json.Number("18446744073709551615"), uint64(math.MaxUint64)},
+		{"int8 min", json.Number("-128"), int8(math.MinInt8)},
+		{"uint16 max", json.Number("65535"), uint16(math.MaxUint16)},
+		{"int exponent", json.Number("1e3"), 1000},
+		{"int fraction", json.Number("2.0"), uint32(2)},
+		{"uint64 exponent", json.Number("1e19"), uint64(1e19)},
+		{"float32", json.Number("0.5"), float32(0.5)},
+		{"float64", json.Number("1.7976931348623157e308"), math.MaxFloat64},
+		{"float64 from legacy", 0.25, 0.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &dto.Param{Order: 1, Kind: reflect.TypeOf(tt.want).Kind().String(), Value: tt.value}
+			value, err := transmission.DecryptParam(p, reflect.TypeOf(tt.want), nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, value.Interface())
+		})
+	}
+}
+
+func TestDecryptNumberOverflow(t *testing.T) {
+	var tests = []struct {
+		name  string
+		value any
+		typ   any
+	}{
+		{"int8", json.Number("128"), int8(0)},
+		{"int8 negative", json.Number("-129"), int8(0)},
+		{"uint16", json.Number("65536"), uint16(0)},
+		{"uint negative", json.Number("-1"), uint(0)},
+		{"int64", json.Number("9223372036854775808"), int64(0)},
+		{"uint64", json.Number("18446744073709551616"), uint64(0)},
+		{"float32", json.Number("1e39"), float32(0)},
+		{"fraction", json.Number("1.5"), 0},
+		{"huge exponent", json.Number("1e10000000"), int64(0)},
+		{"huge exponent uint", json.Number("1e10000000"), uint64(0)},
+		{"exponent beyond 64 bits", json.Number("1e20"), uint64(0)},
+		{"long fraction", json.Number("1." + strings.Repeat("0", 1000)), 0},
+		{"not a number", true, 0},
+		{"quoted", "18446744073709551615", uint64(0)},
+		{"quoted float", "0.5", float64(0)},
+		{"NaN", json.Number("NaN"), float64(0)},
+		{"Inf", json.Number("-Inf"), float32(0)},
+		{"complex Inf", []any{json.Number("Inf"), json.Number("0")}, complex128(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			p := &dto.Param{Order: 1, Kind: reflect.TypeOf(tt.typ).Kind().String(), Value: tt.value}
+			_, err := transmission.DecryptParam(p, reflect.TypeOf(tt.typ), nil)
+			var convertError *dto.ConvertError
+			assert.True(t, errors.As(err, &convertError), "%v", err)
+			assert.Less(t, time.Since(start), 100*time.Millisecond)
+		})
+	}
+}
+
+type Quoted struct {
+	N uint64  `json:",string"`
+	F float64 `json:",string"`
+	M map[int]string
+}
+
+func TestDecryptQuotedNumber(t *testing.T) {
+	in := reflect.TypeOf(Quoted{})
+	p := &dto.Param{Order: 1, Kind: "struct", Value: map[string]any{
+		"N": "18446744073709551615",
+		"F": "0.5",
+		"M": map[string]any{"-1": "a"},
+	}}
+	value, err := transmission.DecryptParam(p, in, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Quoted{N: math.MaxUint64, F: 0.5, M: map[int]string{-1: "a"}}, value.Interface())
+
+	for name, v := range map[string]map[string]any{
+		"NaN":            {"F": "NaN"},
+		"not quoted":     {"N": json.Number("1")},
+		"map key format": {"M": map[string]any{"1e3": "a"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := transmission.DecryptParam(&dto.Param{Order: 1, Kind: "struct", Value: v}, in, nil)
+			var convertError *dto.ConvertError
+			assert.True(t, errors.As(err, &convertError), "%v", err)
+		})
+	}
+}
+
+func TestDecryptArrayExtraElements(t *testing.T) {
+	p := &dto.Param{Order: 1, Kind: "array", Value: []any{json.Number("1"), json.Number("2"), json.Number("3")}}
+	value, err := transmission.DecryptParam(p, reflect.TypeOf([2]int{}), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, [2]int{1, 2}, value.Interface())
+}
@@ -0,0 +1,118 @@
+package validation
+
+import (
+	"errors"
+	"github.com/go-kid/remote-ioc/http/dto"
+	"github.com/go-kid/remote-ioc/http/validation"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+type Address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type Line struct {
+	Sku string `json:"sku" validate:"required"`
+	Qty int    `json:"qty" validate:"min=1,max=100"`
+}
+
+type Base struct {
+	Id string `json:"id" validate:"len=4"`
+}
+
+type Order struct {
+	Base
+	Email   string           `json:"email" validate:"required,email"`
+	Note    string           `json:"note" validate:"omitempty,min=3"`
+	Status  string           `json:"status" validate:"oneof=new paid"`
+	Address *Address         `json:"address" validate:"required"`
+	Lines   []Line           `json:"lines" validate:"required,max=3"`
+	Tags    map[string]*Line `json:"tags"`
+	Ignored string           `json:"-" validate:"required"`
+	Even    int              `validate:"even"`
+}
+
+func valid() *Order {
+	return &Order{
+		Base:    Base{Id: "A001"},
+		Email:   "a@example.com",
+		Status:  "new",
+		Address: &Address{City: "Berlin"},
+		Lines:   []Line{{Sku: "x", Qty: 1}},
+	}
+}
+
+func init() {
+	validation.RegisterRule("even", func(v reflect.Value, _ string) error {
+		if v.Int()%2 != 0 {
+			return errors.New("must be even")
+		}
+		return nil
+	})
+}
+
+func TestValue(t *testing.T) {
+	var tests = []struct {
+		name   string
+		modify func(o *Order)
+		field  string
+		rule   string
+	}{
+		{"Valid", func(o *Order) {}, "", ""},
+		{"Embedded", func(o *Order) { o.Id = "A1" }, "id", "len"},
+		{"Required", func(o *Order) { o.Email = "" }, "email", "required"},
+		{"Email", func(o *Order) { o.Email = "Bob <a@example.com>" }, "email", "email"},
+		{"OmitEmpty", func(o *Order) { o.Note = "" }, "", ""},
+		{"OmitEmptySet", func(o *Order) { o.Note = "ab" }, "note", "min"},
+		{"OneOf", func(o *Order) { o.Status = "lost" }, "status", "oneof"},
+		{"NilPointer", func(o *Order) { o.Address = nil }, "address", "required"},
+		{"Nested", func(o *Order) { o.Address.City = "" }, "address.city", "required"},
+		{"EmptySlice", func(o *Order) { o.Lines = []Line{} }, "lines", "required"},
+		{"SliceLength", func(o *Order) { o.Lines = make([]Line, 4) }, "lines", "max"},
+		{"SliceElement", func(o *Order) { o.Lines = append(o.Lines, Line{Sku: "y", Qty: 101}) }, "lines[1].qty", "max"},
+		{"MapElement", func(o *Order) { o.Tags = map[string]*Line{"b": {Sku: "b", Qty: 1}, "a": {Qty: 1}} }, "tags[a].sku", "required"},
+		{"Custom", func(o *Order) { o.Even = 3 }, "Even", "even"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := valid()
+			tt.modify(o)
+			violation := validation.Value(reflect.ValueOf(o))
+			if tt.field == "" {
+				assert.Nil(t, violation)
+				return
+			}
+			if assert.NotNil(t, violation) {
+				assert.Equal(t, tt.field, violation.Field)
+				assert.Equal(t, tt.rule, violation.Rule)
+			}
+		})
+	}
+}
+
+func TestParam(t *testing.T) {
+	o := valid()
+	o.Lines[0].Qty = 0
+	err := validation.Param(2, reflect.ValueOf(o))
+	var validateErr *dto.ValidateError
+	if assert.ErrorAs(t, err, &validateErr) {
+		assert.Equal(t, 2, validateErr.ParamOrder)
+		assert.Equal(t, "lines[0].qty", validateErr.Field)
+		assert.Equal(t, "min", validateErr.Rule)
+		assert.Equal(t, "lines[0].qty: must be at least 1", validateErr.Msg)
+		assert.Equal(t, 0, validateErr.Value)
+	}
+	assert.NoError(t, validation.Param(1, reflect.ValueOf(42)))
+}
+
+func TestUnknownRule(t *testing.T) {
+	var v = struct {
+		Name string `validate:"missing"`
+	}{}
+	violation := validation.Value(reflect.ValueOf(v))
+	if assert.NotNil(t, violation) {
+		assert.Equal(t, "missing", violation.Rule)
+	}
+}